@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReady reports whether policy configuration is valid
+	ConditionReady = "Ready"
+	// ConditionAssigned reports result of the last pod-deletion-cost assignment
+	ConditionAssigned = "Assigned"
+)
+
+// PodDeletionCostPolicySpec defines desired pod-deletion-cost configuration of selected workloads
+type PodDeletionCostPolicySpec struct {
+	// Selector selects workloads (Deployments, ReplicaSets without owner, configured owner kinds)
+	// in namespace of the policy by their labels. Empty selector selects all workloads.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Algorithm used for pod-deletion-cost distribution, same as pod-deletion-cost.lablabs.io/type annotation.
+	// Empty value selects default algorithm.
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Parameters of algorithm. Keys are names of pod-deletion-cost.lablabs.io/* annotations without prefix,
+	// e.g. spread-by or mode.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// WorkloadReference references workload matched by policy
+type WorkloadReference struct {
+	// Kind of workload
+	Kind string `json:"kind"`
+	// Name of workload
+	Name string `json:"name"`
+}
+
+// PodDeletionCostPolicyStatus defines observed state of PodDeletionCostPolicy
+type PodDeletionCostPolicyStatus struct {
+	// ObservedGeneration is the last generation reconciled by controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// MatchedWorkloads lists workloads governed by the policy
+	// +optional
+	MatchedWorkloads []WorkloadReference `json:"matchedWorkloads,omitempty"`
+
+	// LastAssignmentTime is time of the last pod-deletion-cost assignment to Pod of matched workload
+	// +optional
+	LastAssignmentTime *metav1.Time `json:"lastAssignmentTime,omitempty"`
+
+	// Conditions report configuration and assignment errors
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=pdcp
+// +kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.spec.algorithm`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// PodDeletionCostPolicy configures pod-deletion-cost management of workloads selected by labels.
+// It is an alternative to pod-deletion-cost.lablabs.io/* annotations on workloads.
+type PodDeletionCostPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PodDeletionCostPolicySpec   `json:"spec,omitempty"`
+	Status PodDeletionCostPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PodDeletionCostPolicyList contains a list of PodDeletionCostPolicy
+type PodDeletionCostPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PodDeletionCostPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PodDeletionCostPolicy{}, &PodDeletionCostPolicyList{})
+}
@@ -20,7 +20,12 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/lablabs/pod-deletion-cost-controller/api/v1alpha1"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/node"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/nodeprice"
+	webhookv1 "github.com/lablabs/pod-deletion-cost-controller/internal/webhook/v1"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -31,14 +36,20 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/lablabs/pod-deletion-cost-controller/internal/age"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/capacitytype"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/consolidate"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/health"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -53,12 +64,17 @@ func (s *sliceFlag) String() string {
 }
 
 func (s *sliceFlag) Set(value string) error {
-	*s = append(*s, value)
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
 	return nil
 }
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
@@ -68,7 +84,18 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableRollouts bool
+	var defaultPolicyFile string
+	var nodePriceFile string
+	var healthDebounce time.Duration
+	var watchNodeOccupancy bool
+	var watchPodStatus bool
+	var enableMutatingWebhook bool
+	var enableValidatingWebhook bool
+	var webhookPort int
+	var webhookCertDir string
 	algoType := sliceFlag{}
+	ownerKind := sliceFlag{}
 	// Register the flag
 	flag.Var(&algoType, "algorithm-type", "List of algorithm type to use in controller for pod-deletion-cost distribution")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
@@ -77,33 +104,95 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", true,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableRollouts, "argo-rollouts", false,
+		"Enable Argo Rollouts as owner of ReplicaSets. Requires argoproj.io Rollout CRD installed in cluster.")
+	flag.Var(&ownerKind, "owner-kind", "List of additional kinds owning ReplicaSets in format group/version/Kind, "+
+		"e.g. argoproj.io/v1alpha1/Rollout")
+	flag.StringVar(&defaultPolicyFile, "default-policy-file", "",
+		"Path to YAML file with cluster-wide default policy applied to workloads in namespaces matching namespaceSelector")
+	flag.StringVar(&nodePriceFile, "node-price-file", "",
+		"Path to YAML file with price table of node-price algorithm, mapping values of node price label to prices")
+	flag.BoolVar(&watchNodeOccupancy, "watch-node-occupancy", false,
+		"Re-rank Pods of consolidate algorithm when other Pods are bound to or leave their Node. "+
+			"Watches all Pods of cluster, otherwise ranking follows occupancy on reconciles of workload Pods only.")
+	flag.BoolVar(&watchPodStatus, "watch-pod-status", false,
+		"Re-rank Pods of health algorithm on status changes, e.g. container restarts or readiness transitions. "+
+			"Status-only updates of Pods with cost are then reconciled after health-debounce delay.")
+	flag.DurationVar(&healthDebounce, "health-debounce", 30*time.Second,
+		"Delay of re-ranking Pods of health algorithm on status changes, changes within delay are merged")
+	flag.StringVar(&controller.LegacyFieldManager, "legacy-field-manager", "",
+		"Field manager of costs written by controller releases before server-side apply, e.g. manager. "+
+			"Costs updated by it are migrated to controller ownership. Empty disables migration.")
+	flag.BoolVar(&enableMutatingWebhook, "enable-mutating-webhook", false,
+		"Enable mutating webhook assigning provisional pod-deletion-cost to Pods at creation.")
+	flag.BoolVar(&enableValidatingWebhook, "enable-validating-webhook", false,
+		"Enable validating webhook of pod-deletion-cost.lablabs.io annotations of Deployments.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server binds to.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "",
+		"The directory with tls.crt and tls.key of webhook server. Defaults to controller-runtime default directory.")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	logger := zap.New(zap.UseFlagOptions(&opts))
 	ctrl.SetLogger(logger)
+	ownerKinds := make([]schema.GroupVersionKind, 0, len(ownerKind))
+	for _, k := range ownerKind {
+		gvk, err := controller.ParseOwnerKind(k)
+		if err != nil {
+			logger.Error(err, "unable to parse owner kind")
+			os.Exit(1)
+		}
+		ownerKinds = append(ownerKinds, gvk)
+	}
+	if enableRollouts {
+		ownerKinds = append(ownerKinds, controller.RolloutGVK)
+	}
+	var defaultPolicy *controller.DefaultPolicy
+	if defaultPolicyFile != "" {
+		var err error
+		if defaultPolicy, err = controller.LoadDefaultPolicy(defaultPolicyFile); err != nil {
+			logger.Error(err, "unable to load default policy")
+			os.Exit(1)
+		}
+	}
+	nodePrices := nodeprice.Prices{}
+	if nodePriceFile != "" {
+		var err error
+		if nodePrices, err = nodeprice.LoadPrices(nodePriceFile); err != nil {
+			logger.Error(err, "unable to load node price table")
+			os.Exit(1)
+		}
+	}
 	metricsServerOptions := metricsserver.Options{
 		BindAddress: metricsAddr,
 	}
+	webhookServer := webhook.NewServer(webhook.Options{
+		Port:    webhookPort,
+		CertDir: webhookCertDir,
+	})
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		HealthProbeBindAddress: probeAddr,
+		WebhookServer:          webhookServer,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "8bc3731b.pod-deletion-cost-controller.lablabs.io",
 		// Enable strict mode
 		Cache: cache.Options{
 			ByObject: map[client.Object]cache.ByObject{
-				&corev1.Node{}:   {},
-				&corev1.Pod{}:    {},
-				&v1.ReplicaSet{}: {},
-				&v1.Deployment{}: {},
+				&corev1.Node{}:                    {},
+				&corev1.Namespace{}:               {},
+				&corev1.Pod{}:                     {},
+				&v1.ReplicaSet{}:                  {},
+				&v1.Deployment{}:                  {},
+				&v1alpha1.PodDeletionCostPolicy{}: {},
 			},
 		},
 		Client: client.Options{
 			Cache: &client.CacheOptions{
-				DisableFor: []client.Object{}, // disable no types
+				DisableFor:   []client.Object{}, // disable no types
+				Unstructured: len(ownerKinds) > 0,
 			},
 		},
 	})
@@ -120,14 +209,90 @@ func main() {
 		logger.Error(err, "unable to register zone")
 		os.Exit(1)
 	}
+	err = node.Register(logger, moduleMng, mgr.GetClient(), algoType)
+	if err != nil {
+		logger.Error(err, "unable to register node")
+		os.Exit(1)
+	}
+	err = capacitytype.Register(logger, moduleMng, mgr.GetClient(), algoType)
+	if err != nil {
+		logger.Error(err, "unable to register capacity-type")
+		os.Exit(1)
+	}
+	err = nodeprice.Register(logger, moduleMng, mgr.GetClient(), algoType, nodePrices)
+	if err != nil {
+		logger.Error(err, "unable to register node-price")
+		os.Exit(1)
+	}
+	err = consolidate.Register(logger, moduleMng, mgr.GetClient(), algoType)
+	if err != nil {
+		logger.Error(err, "unable to register consolidate")
+		os.Exit(1)
+	}
+	err = age.Register(logger, moduleMng, mgr.GetClient(), algoType)
+	if err != nil {
+		logger.Error(err, "unable to register age")
+		os.Exit(1)
+	}
+	err = health.Register(logger, moduleMng, mgr.GetClient(), algoType)
+	if err != nil {
+		logger.Error(err, "unable to register health")
+		os.Exit(1)
+	}
+	policiesInstalled, err := controller.PolicyInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		logger.Error(err, "unable to check PodDeletionCostPolicy CRD")
+		os.Exit(1)
+	}
+	if !policiesInstalled {
+		logger.Info("PodDeletionCostPolicy CRD is not installed, policies are disabled")
+	}
 	if err := (&controller.PodReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Manager: moduleMng,
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Manager:         moduleMng,
+		OwnerKinds:      ownerKinds,
+		DefaultPolicy:   defaultPolicy,
+		DisablePolicies: !policiesInstalled,
+		WatchStatus:     watchPodStatus && moduleMng.HasType(health.TypeAnnotation),
+		WatchNeighbours: watchNodeOccupancy && moduleMng.HasType(consolidate.TypeAnnotation),
+		StatusDebounce:  healthDebounce,
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "unable to create controller", "controller", "Pod")
 		os.Exit(1)
 	}
+	if policiesInstalled {
+		if err := (&controller.PolicyReconciler{
+			Client:     mgr.GetClient(),
+			Scheme:     mgr.GetScheme(),
+			Manager:    moduleMng,
+			OwnerKinds: ownerKinds,
+		}).SetupWithManager(mgr); err != nil {
+			logger.Error(err, "unable to create controller", "controller", "PodDeletionCostPolicy")
+			os.Exit(1)
+		}
+	}
+	if enableMutatingWebhook {
+		resolver := controller.NewWorkloadResolver(mgr.GetClient(), ownerKinds...).WithDefaultPolicy(defaultPolicy)
+		if !policiesInstalled {
+			resolver.WithoutPolicies()
+		}
+		if err := webhookv1.SetupPodWebhookWithManager(mgr, &webhookv1.PodCostDefaulter{
+			Manager:  moduleMng,
+			Resolver: resolver,
+		}); err != nil {
+			logger.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+	}
+	if enableValidatingWebhook {
+		if err := webhookv1.SetupDeploymentWebhookWithManager(mgr, &webhookv1.DeploymentValidator{
+			Manager: moduleMng,
+		}); err != nil {
+			logger.Error(err, "unable to create webhook", "webhook", "Deployment")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
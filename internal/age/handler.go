@@ -0,0 +1,100 @@
+package age
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeAnnotation name of algo type
+	TypeAnnotation = "age"
+	// OrderAnnotation selects which Pods are removed first. Default is OrderNewestFirst
+	OrderAnnotation = "pod-deletion-cost.lablabs.io/order"
+	// OrderNewestFirst removes the newest Pods first
+	OrderNewestFirst = "newest-first"
+	// OrderOldestFirst removes the oldest Pods first (FIFO)
+	OrderOldestFirst = "oldest-first"
+	// FromAnnotation selects time age of Pod is measured from. Default is FromCreated
+	FromAnnotation = "pod-deletion-cost.lablabs.io/age-from"
+	// FromCreated measures age from creationTimestamp of Pod
+	FromCreated = "created"
+	// FromReady measures age from last transition of Ready condition of Pod. Pods not Ready are removed first
+	// in both orders
+	FromReady = "ready"
+)
+
+// Handler ranks Pods by their age, optionally inside zone balancing
+type Handler struct {
+	*zone.Handler
+}
+
+// NewHandler create new Handler
+func NewHandler(client client.Client) *Handler {
+	return &Handler{Handler: zone.NewSortHandler(client, []string{TypeAnnotation}, Sort)}
+}
+
+// Validate validates age annotations of workload in addition to zone annotations
+func (h *Handler) Validate(ctx context.Context, w *module.Workload) ([]string, field.ErrorList) {
+	warnings, errs := h.Handler.Validate(ctx, w)
+	annotations := field.NewPath("metadata", "annotations")
+	if order, ok := w.GetAnnotations()[OrderAnnotation]; ok && order != OrderNewestFirst && order != OrderOldestFirst {
+		errs = append(errs, field.NotSupported(annotations.Key(OrderAnnotation), order,
+			[]string{OrderNewestFirst, OrderOldestFirst}))
+	}
+	if from, ok := w.GetAnnotations()[FromAnnotation]; ok && from != FromCreated && from != FromReady {
+		errs = append(errs, field.NotSupported(annotations.Key(FromAnnotation), from, []string{FromCreated, FromReady}))
+	}
+	return warnings, errs
+}
+
+// Sort orders Pods from the most protected to the least protected one by OrderAnnotation of workload.
+// Pods of the same age are ordered by name. When age is measured FromReady, Pods not Ready have no age
+// and are ordered below Ready Pods by creationTimestamp
+func Sort(pods []corev1.Pod, w *module.Workload) {
+	fromReady := w.GetAnnotations()[FromAnnotation] == FromReady
+	oldestFirst := w.GetAnnotations()[OrderAnnotation] == OrderOldestFirst
+	sort.SliceStable(pods, func(i, j int) bool {
+		if fromReady {
+			if ri, rj := isReady(&pods[i]), isReady(&pods[j]); ri != rj {
+				return ri
+			}
+		}
+		ti, tj := Since(&pods[i], fromReady), Since(&pods[j], fromReady)
+		if !ti.Equal(tj) {
+			// newest Pods are removed first, so the oldest are the most protected
+			return ti.Before(tj) != oldestFirst
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
+
+// Since return time age of Pod is measured from. Pods not Ready use creationTimestamp when fromReady is true
+func Since(pod *corev1.Pod, fromReady bool) time.Time {
+	if fromReady {
+		if c := readyCondition(pod); c != nil && c.Status == corev1.ConditionTrue && !c.LastTransitionTime.IsZero() {
+			return c.LastTransitionTime.Time
+		}
+	}
+	return pod.CreationTimestamp.Time
+}
+
+func isReady(pod *corev1.Pod) bool {
+	c := readyCondition(pod)
+	return c != nil && c.Status == corev1.ConditionTrue
+}
+
+func readyCondition(pod *corev1.Pod) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == corev1.PodReady {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package age_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/age"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newPod(name, nodeName string, created, ready time.Duration) *corev1.Pod {
+	pod := testutil.NewPod(name, "app-1", nodeName, 0)
+	pod.CreationTimestamp = v1.NewTime(now.Add(created))
+	pod.Status.Conditions[0].LastTransitionTime = v1.NewTime(now.Add(ready))
+	return pod
+}
+
+func TestSort(t *testing.T) {
+	// "restarted" became ready last, "unready" is the oldest pod, but it is not ready
+	unready := newPod("unready", "", -4*time.Hour, -time.Minute)
+	unready.Status.Conditions[0].Status = corev1.ConditionFalse
+	pods := []corev1.Pod{
+		*newPod("middle", "", -2*time.Hour, -2*time.Hour),
+		*unready,
+		*newPod("newest", "", -time.Hour, -time.Hour),
+		*newPod("restarted", "", -3*time.Hour, -time.Minute),
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{
+			name: "newest first by default",
+			want: []string{"unready", "restarted", "middle", "newest"},
+		},
+		{
+			name:        "oldest first",
+			annotations: map[string]string{age.OrderAnnotation: age.OrderOldestFirst},
+			want:        []string{"newest", "middle", "restarted", "unready"},
+		},
+		{
+			name:        "newest first from ready transition",
+			annotations: map[string]string{age.FromAnnotation: age.FromReady},
+			want:        []string{"middle", "newest", "restarted", "unready"},
+		},
+		{
+			name: "oldest first from ready transition",
+			annotations: map[string]string{
+				age.FromAnnotation:  age.FromReady,
+				age.OrderAnnotation: age.OrderOldestFirst,
+			},
+			want: []string{"restarted", "newest", "middle", "unready"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := module.FromDeployment(&appsv1.Deployment{ObjectMeta: v1.ObjectMeta{Annotations: tt.annotations}})
+			sorted := append([]corev1.Pod(nil), pods...)
+			age.Sort(sorted, w)
+			got := make([]string, 0, len(sorted))
+			for _, p := range sorted {
+				got = append(got, p.Name)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			UID:         "app",
+			Annotations: map[string]string{age.OrderAnnotation: age.OrderOldestFirst},
+		},
+	}
+	pods := []*corev1.Pod{
+		newPod("a-old", "node-a", -3*time.Hour, -3*time.Hour),
+		newPod("a-new", "node-a", -time.Hour, -time.Hour),
+		newPod("b-old", "node-b", -2*time.Hour, -2*time.Hour),
+	}
+	objs := []client.Object{
+		testutil.NewNode("node-a", map[string]string{zone.TopologyZoneAnnotation: "a"}),
+		testutil.NewNode("node-b", map[string]string{zone.TopologyZoneAnnotation: "b"}),
+	}
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	c := testutil.NewFakeClient(objs...)
+
+	h := age.NewHandler(c)
+	require.Equal(t, []string{age.TypeAnnotation}, h.AcceptType())
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), pods[0], module.FromDeployment(dep)))
+
+	// zones are balanced first, the oldest pod of the larger zone is removed first
+	testutil.RequireCosts(t, c, map[string]int{
+		"b-old": math.MaxInt32,
+		"a-new": math.MaxInt32 - 1,
+		"a-old": math.MaxInt32 - 2,
+	})
+}
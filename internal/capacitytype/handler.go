@@ -0,0 +1,72 @@
+package capacitytype
+
+import (
+	"strings"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeAnnotation name of algo type
+	TypeAnnotation = "capacity-type"
+	// LabelAnnotation overrides Node label keys holding capacity type as comma separated list.
+	// The first label present on Node is used
+	LabelAnnotation = "pod-deletion-cost.lablabs.io/capacity-type-label"
+	// KarpenterLabel capacity type label of Nodes provisioned by Karpenter
+	KarpenterLabel = "karpenter.sh/capacity-type"
+	// EKSLabel capacity type label of EKS managed node groups
+	EKSLabel = "eks.amazonaws.com/capacityType"
+	// BandSpot band of Pods running on spot Nodes, removed first
+	BandSpot = 0
+	// BandOnDemand band of Pods running on other Nodes
+	BandOnDemand = 1
+)
+
+// DefaultLabels Node label keys holding capacity type when LabelAnnotation is not set
+var DefaultLabels = []string{KarpenterLabel, EKSLabel}
+
+// NewHandler create new Handler ranking Pods on spot Nodes below Pods on on-demand Nodes. Pods are spread
+// by zone inside each band, zone annotations are supported
+func NewHandler(client client.Client) *zone.Handler {
+	return zone.NewBandHandler(client, []string{TypeAnnotation}, Band)
+}
+
+// Band return BandSpot for Pod running on spot Node, BandOnDemand otherwise. Node is spot when its capacity type
+// label is "spot" (case-insensitive) or "true"
+func Band(node *corev1.Node, _ *corev1.Pod, w *module.Workload) int {
+	if node == nil {
+		return BandOnDemand
+	}
+	for _, key := range Labels(w) {
+		value, ok := node.Labels[key]
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(value, "spot") || value == "true" {
+			return BandSpot
+		}
+		return BandOnDemand
+	}
+	return BandOnDemand
+}
+
+// Labels return Node label keys holding capacity type, LabelAnnotation keys or DefaultLabels
+func Labels(w *module.Workload) []string {
+	value, ok := w.GetAnnotations()[LabelAnnotation]
+	if !ok {
+		return DefaultLabels
+	}
+	keys := make([]string, 0)
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return DefaultLabels
+	}
+	return keys
+}
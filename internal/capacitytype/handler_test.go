@@ -0,0 +1,106 @@
+package capacitytype_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/capacitytype"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newNode(name, zoneName, capacityType string) *corev1.Node {
+	return testutil.NewNode(name, map[string]string{
+		zone.TopologyZoneAnnotation: zoneName,
+		capacitytype.KarpenterLabel: capacityType,
+	})
+}
+
+func TestBand(t *testing.T) {
+	w := module.FromDeployment(&appsv1.Deployment{})
+	eks := &corev1.Node{ObjectMeta: v1.ObjectMeta{Labels: map[string]string{capacitytype.EKSLabel: "SPOT"}}}
+	tests := []struct {
+		name        string
+		node        *corev1.Node
+		annotations map[string]string
+		want        int
+	}{
+		{
+			name: "karpenter spot",
+			node: newNode("n", "a", "spot"),
+			want: capacitytype.BandSpot,
+		},
+		{
+			name: "karpenter on-demand",
+			node: newNode("n", "a", "on-demand"),
+			want: capacitytype.BandOnDemand,
+		},
+		{
+			name: "eks spot",
+			node: eks,
+			want: capacitytype.BandSpot,
+		},
+		{
+			name: "no label",
+			node: &corev1.Node{},
+			want: capacitytype.BandOnDemand,
+		},
+		{
+			name:        "custom label",
+			node:        &corev1.Node{ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"cloud.google.com/gke-spot": "true"}}},
+			annotations: map[string]string{capacitytype.LabelAnnotation: "cloud.google.com/gke-spot"},
+			want:        capacitytype.BandSpot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.SetAnnotations(tt.annotations)
+			require.Equal(t, tt.want, capacitytype.Band(tt.node, nil, w))
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	pods := []*corev1.Pod{
+		testutil.NewPod("spot-a", "app-1", "spot-a", math.MaxInt32),
+		testutil.NewPod("spot-b", "app-1", "spot-b", math.MaxInt32),
+		testutil.NewPod("on-demand-a-1", "app-1", "on-demand-a", math.MaxInt32-1),
+		testutil.NewPod("on-demand-a-2", "app-1", "on-demand-a", math.MaxInt32-2),
+		testutil.NewPod("on-demand-b", "app-1", "on-demand-b", math.MaxInt32-1),
+	}
+	objs := []client.Object{
+		newNode("spot-a", "a", "spot"),
+		newNode("spot-b", "b", "spot"),
+		newNode("on-demand-a", "a", "on-demand"),
+		newNode("on-demand-b", "b", "on-demand"),
+	}
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	c := testutil.NewFakeClient(objs...)
+
+	h := capacitytype.NewHandler(c)
+	require.Equal(t, []string{capacitytype.TypeAnnotation}, h.AcceptType())
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), pods[0], module.FromDeployment(dep)))
+
+	// on-demand band is above spot band, zones are balanced inside bands
+	testutil.RequireCosts(t, c, map[string]int{
+		"on-demand-b":   math.MaxInt32,
+		"on-demand-a-1": math.MaxInt32 - 1,
+		"on-demand-a-2": math.MaxInt32 - 2,
+		"spot-a":        math.MaxInt32 - 3,
+		"spot-b":        math.MaxInt32 - 4,
+	})
+}
@@ -0,0 +1,116 @@
+package consolidate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeAnnotation name of algo type
+	TypeAnnotation = "consolidate"
+	// ByAnnotation selects how Node occupancy is measured. Default is ByPods
+	ByAnnotation = "pod-deletion-cost.lablabs.io/consolidate-by"
+	// ByPods measures Node occupancy by number of Pods
+	ByPods = "pods"
+	// ByRequests measures Node occupancy by CPU and memory requests of Pods relative to Node allocatable,
+	// the higher of both is used
+	ByRequests = "requests"
+)
+
+// Handler ranks Pods on the least occupied Nodes lowest, so scale-down empties Nodes which can be reclaimed
+// by cluster autoscaler or Karpenter
+type Handler struct {
+	*zone.Handler
+	client client.Client
+}
+
+// NewHandler create new Handler
+func NewHandler(client client.Client) *Handler {
+	h := &Handler{client: client}
+	h.Handler = zone.NewNodeRankHandler(client, []string{TypeAnnotation}, h.rank)
+	return h
+}
+
+// Validate validates consolidate annotations of workload in addition to zone annotations
+func (h *Handler) Validate(ctx context.Context, w *module.Workload) ([]string, field.ErrorList) {
+	warnings, errs := h.Handler.Validate(ctx, w)
+	if by, ok := w.GetAnnotations()[ByAnnotation]; ok && by != ByPods && by != ByRequests {
+		errs = append(errs, field.NotSupported(field.NewPath("metadata", "annotations").Key(ByAnnotation), by,
+			[]string{ByPods, ByRequests}))
+	}
+	return warnings, errs
+}
+
+// rank ranks Nodes from the most occupied one, ties are broken by Node name
+func (h *Handler) rank(ctx context.Context, nodes map[string]*corev1.Node, w *module.Workload) (map[string]int, error) {
+	occupancy := make(map[string]int64, len(nodes))
+	names := make([]string, 0, len(nodes))
+	for name, node := range nodes {
+		pods := &corev1.PodList{}
+		if err := h.client.List(ctx, pods, client.MatchingFields{controller.PodToNodeIndex: name}); err != nil {
+			return nil, fmt.Errorf("unable to list pods of node %s: %w", name, err)
+		}
+		occupancy[name] = Occupancy(node, pods.Items, w.GetAnnotations()[ByAnnotation] == ByRequests)
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if occupancy[names[i]] != occupancy[names[j]] {
+			return occupancy[names[i]] > occupancy[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	ranks := make(map[string]int, len(names))
+	for i, name := range names {
+		ranks[name] = i
+	}
+	return ranks, nil
+}
+
+// Occupancy return number of Pods running on Node, or requests of Pods relative to Node allocatable in permille
+// when byRequests is true. Finished Pods and Pods of DaemonSets are not counted, they do not block Node removal
+func Occupancy(node *corev1.Node, pods []corev1.Pod, byRequests bool) int64 {
+	count := int64(0)
+	cpu := resource.Quantity{}
+	memory := resource.Quantity{}
+	for i := range pods {
+		p := &pods[i]
+		if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed || isDaemonSetPod(p) {
+			continue
+		}
+		count++
+		for _, c := range p.Spec.Containers {
+			cpu.Add(c.Resources.Requests[corev1.ResourceCPU])
+			memory.Add(c.Resources.Requests[corev1.ResourceMemory])
+		}
+	}
+	if !byRequests {
+		return count
+	}
+	return max(permille(cpu, node.Status.Allocatable[corev1.ResourceCPU]),
+		permille(memory, node.Status.Allocatable[corev1.ResourceMemory]))
+}
+
+func permille(requested, allocatable resource.Quantity) int64 {
+	if allocatable.IsZero() {
+		return 0
+	}
+	return requested.MilliValue() * 1000 / allocatable.MilliValue()
+}
+
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
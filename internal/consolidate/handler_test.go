@@ -0,0 +1,86 @@
+package consolidate_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/consolidate"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestOccupancy(t *testing.T) {
+	node := &corev1.Node{Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}}}
+	withRequests := func(cpu, memory string) corev1.Pod {
+		return corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}}}}
+	}
+	finished := withRequests("1", "1Gi")
+	finished.Status.Phase = corev1.PodSucceeded
+	daemon := withRequests("1", "1Gi")
+	daemon.OwnerReferences = []v1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}}
+	pods := []corev1.Pod{withRequests("1", "1Gi"), withRequests("500m", "3Gi"), finished, daemon}
+
+	require.Equal(t, int64(2), consolidate.Occupancy(node, pods, false))
+	// cpu 1.5/4 = 375‰, memory 4Gi/8Gi = 500‰
+	require.Equal(t, int64(500), consolidate.Occupancy(node, pods, true))
+}
+
+func TestHandle(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	pods := []*corev1.Pod{
+		testutil.NewPod("busy-1", "app-1", "busy", math.MaxInt32),
+		testutil.NewPod("busy-2", "app-1", "busy", math.MaxInt32-1),
+		testutil.NewPod("idle-1", "app-1", "idle", math.MaxInt32),
+		testutil.NewPod("idle-2", "app-1", "idle", math.MaxInt32-1),
+		testutil.NewPod("other-1", "other", "busy", 0),
+		testutil.NewPod("other-2", "other", "busy", 0),
+	}
+	objs := []client.Object{
+		testutil.NewNode("busy", nil),
+		testutil.NewNode("idle", nil),
+	}
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	c := testutil.NewFakeClient(objs...)
+
+	h := consolidate.NewHandler(c)
+	require.Equal(t, []string{consolidate.TypeAnnotation}, h.AcceptType())
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), pods[0], module.FromDeployment(dep)))
+
+	// pods of the node with fewer pods are removed first, so the node can be emptied
+	testutil.RequireCosts(t, c, map[string]int{
+		"busy-1": math.MaxInt32,
+		"busy-2": math.MaxInt32 - 1,
+		"idle-1": math.MaxInt32 - 2,
+		"idle-2": math.MaxInt32 - 3,
+	})
+}
+
+func TestValidate(t *testing.T) {
+	c := testutil.NewFakeClient()
+	h := consolidate.NewHandler(c)
+	dep := &appsv1.Deployment{ObjectMeta: v1.ObjectMeta{
+		Annotations: map[string]string{consolidate.ByAnnotation: "cpu"},
+	}}
+	_, errs := h.Validate(context.Background(), module.FromDeployment(dep))
+	require.Len(t, errs, 1)
+}
@@ -1,28 +1,107 @@
 package controller
 
 import (
+	"math"
+	"slices"
 	"strconv"
 
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 const (
 	// PodDeletionCostAnnotation pod-deletion-cost annotation used by k8s to determine Pod for deletion
 	PodDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
-	// EnableAnnotation Use for enable pod-deletion-cost on Deployment. Default annotation used for distribution of cost deletion
+	// EnableAnnotation Use for enable pod-deletion-cost on Deployment or ReplicaSet without owner. Default annotation used for distribution of cost deletion
 	// annotation is 'topology.kubernetes.io/zone'. Can be overridden by SpreadByAnnotation
 	EnableAnnotation = "pod-deletion-cost.lablabs.io/enabled"
 	// TypeAnnotation can be used to specify algorithm used for pod-deletion-cost selection
 	TypeAnnotation = "pod-deletion-cost.lablabs.io/type"
+	// ManagedByAnnotation marks Pods with PodDeletionCostAnnotation set by controller. Only marked Pods are cleaned up
+	// when workload is disabled, so costs set by users are never removed
+	ManagedByAnnotation = "pod-deletion-cost.lablabs.io/managed-by"
+	// ManagedByValue value of ManagedByAnnotation
+	ManagedByValue = "pod-deletion-cost-controller"
+	// DomainAnnotation records spreading domain (e.g. zone) of Node Pod was running on when cost was assigned,
+	// so cost is recomputed when Node labels change
+	DomainAnnotation = "pod-deletion-cost.lablabs.io/domain"
+	// ProvisionalValue value of ManagedByAnnotation marking cost assigned at Pod creation, refined once Pod is Ready
+	ProvisionalValue = "pod-deletion-cost-webhook"
+	// ProvisionalCost initial cost of Pods not bound to Node at creation, the lowest slot of ladder
+	ProvisionalCost = 1
+	// DrainingCost cost of Pods on draining Nodes when ladder is not recomputed, below all slots of ladder
+	DrainingCost = math.MinInt32
+	// ForeignCostAnnotation selects how PodDeletionCostAnnotation not managed by controller (set by user or other tool)
+	// is handled. When not set, foreign costs are handled as ForeignCostInclude in all modes
+	ForeignCostAnnotation = "pod-deletion-cost.lablabs.io/foreign-cost"
+	// ForeignCostRespect never touches foreign costs and excludes Pods with them from ladder
+	ForeignCostRespect = "respect"
+	// ForeignCostOverride replaces foreign costs by controller managed ones
+	ForeignCostOverride = "override"
+	// ForeignCostInclude never touches foreign costs, but counts them as occupied slots of ladder
+	ForeignCostInclude = "include"
 )
 
-// ApplyPodDeletionCost apply PodDeletionCostAnnotation to Pod with value
+// ApplyPodDeletionCost apply PodDeletionCostAnnotation to Pod with value and mark Pod as managed
 func ApplyPodDeletionCost(pod *corev1.Pod, value int) {
 	if pod.Annotations == nil {
 		pod.Annotations = make(map[string]string)
 	}
 	pod.Annotations[PodDeletionCostAnnotation] = strconv.Itoa(value)
+	pod.Annotations[ManagedByAnnotation] = ManagedByValue
+}
+
+// IsDrainingCost return true if Pod has DrainingCost managed by controller
+func IsDrainingCost(pod *corev1.Pod) bool {
+	cost, ok := GetPodDeletionCost(pod)
+	return ok && cost == DrainingCost && !IsForeignCost(pod)
+}
+
+// ApplyProvisionalCost apply PodDeletionCostAnnotation to Pod with value and mark it as provisional
+func ApplyProvisionalCost(pod *corev1.Pod, value int) {
+	ApplyPodDeletionCost(pod, value)
+	pod.Annotations[ManagedByAnnotation] = ProvisionalValue
+}
+
+// IsProvisional return true if Pod has provisional PodDeletionCostAnnotation assigned at creation
+func IsProvisional(pod *corev1.Pod) bool {
+	if pod.Annotations == nil {
+		return false
+	}
+	return HasPodDeletionCost(pod) && pod.Annotations[ManagedByAnnotation] == ProvisionalValue
+}
+
+// NeedsCost return true if PodDeletionCostAnnotation of Pod should be assigned by controller: Pod has no cost,
+// has provisional cost or foreign cost to be overridden
+func NeedsCost(pod *corev1.Pod, workload metav1.Object) bool {
+	return !HasPodDeletionCost(pod) || IsProvisional(pod) || IsOverridden(pod, workload)
+}
+
+// RemovePodDeletionCost remove PodDeletionCostAnnotation and ManagedByAnnotation from Pod
+func RemovePodDeletionCost(pod *corev1.Pod) {
+	delete(pod.Annotations, PodDeletionCostAnnotation)
+	delete(pod.Annotations, ManagedByAnnotation)
+	delete(pod.Annotations, DomainAnnotation)
+}
+
+// GetDomain return domain recorded in DomainAnnotation, false if it is not recorded
+func GetDomain(pod *corev1.Pod) (string, bool) {
+	domain, ok := pod.Annotations[DomainAnnotation]
+	return domain, ok
+}
+
+// IsManaged return true if PodDeletionCostAnnotation of Pod is managed by controller. Ownership is decided
+// by managedFields, ManagedByAnnotation is used when managedFields are not available
+func IsManaged(pod *corev1.Pod) bool {
+	if owned, known := ownsPodDeletionCost(pod); known {
+		return owned
+	}
+	if pod.Annotations == nil {
+		return false
+	}
+	v := pod.Annotations[ManagedByAnnotation]
+	return v == ManagedByValue || v == ProvisionalValue
 }
 
 // GetPodDeletionCost get PodDeletionCostAnnotation
@@ -41,20 +120,52 @@ func GetPodDeletionCost(pod *corev1.Pod) (int, bool) {
 	return value, true
 }
 
-// IsEnabled return true if Deployment has EnableAnnotation enabled
-func IsEnabled(dep *appsv1.Deployment) bool {
-	if dep.Annotations == nil {
+// IsForeignCost return true if Pod has PodDeletionCostAnnotation not managed by controller
+func IsForeignCost(pod *corev1.Pod) bool {
+	return HasPodDeletionCost(pod) && !IsManaged(pod)
+}
+
+// IsOverridden return true if Pod has foreign PodDeletionCostAnnotation which should be replaced by controller
+func IsOverridden(pod *corev1.Pod, workload metav1.Object) bool {
+	return IsForeignCost(pod) && GetForeignCost(workload) == ForeignCostOverride
+}
+
+// GetForeignCost return ForeignCostAnnotation
+func GetForeignCost(obj metav1.Object) string {
+	if obj.GetAnnotations() == nil {
+		return ""
+	}
+	return obj.GetAnnotations()[ForeignCostAnnotation]
+}
+
+// ValidateAnnotations validates values of EnableAnnotation and ForeignCostAnnotation
+func ValidateAnnotations(obj metav1.Object) field.ErrorList {
+	annotations := field.NewPath("metadata", "annotations")
+	errs := field.ErrorList{}
+	if v, ok := obj.GetAnnotations()[EnableAnnotation]; ok && v != "true" && v != "false" {
+		errs = append(errs, field.NotSupported(annotations.Key(EnableAnnotation), v, []string{"true", "false"}))
+	}
+	supported := []string{ForeignCostRespect, ForeignCostOverride, ForeignCostInclude}
+	if v, ok := obj.GetAnnotations()[ForeignCostAnnotation]; ok && !slices.Contains(supported, v) {
+		errs = append(errs, field.NotSupported(annotations.Key(ForeignCostAnnotation), v, supported))
+	}
+	return errs
+}
+
+// IsEnabled return true if workload has EnableAnnotation enabled
+func IsEnabled(obj metav1.Object) bool {
+	if obj.GetAnnotations() == nil {
 		return false
 	}
-	return dep.Annotations[EnableAnnotation] == "true"
+	return obj.GetAnnotations()[EnableAnnotation] == "true"
 }
 
 // GetType return TypeAnnotation
-func GetType(dep *appsv1.Deployment) string {
-	if dep.Annotations == nil {
+func GetType(obj metav1.Object) string {
+	if obj.GetAnnotations() == nil {
 		return ""
 	}
-	return dep.Annotations[TypeAnnotation]
+	return obj.GetAnnotations()[TypeAnnotation]
 }
 
 // HasPodDeletionCost checks if Pod has PodDeletionCostAnnotation
@@ -116,3 +116,41 @@ func TestHasPodDeletionCost(t *testing.T) {
 		})
 	}
 }
+
+func TestRemovePodDeletionCost(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantManaged bool
+	}{
+		{
+			name:        "cost set by controller",
+			annotations: nil,
+			wantManaged: true,
+		},
+		{
+			name: "cost set by user",
+			annotations: map[string]string{
+				controller.PodDeletionCostAnnotation: "100",
+			},
+			wantManaged: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			pod.Annotations = tt.annotations
+			if pod.Annotations == nil {
+				controller.ApplyPodDeletionCost(pod, 100)
+			}
+			if got := controller.IsManaged(pod); got != tt.wantManaged {
+				t.Errorf("IsManaged() = %v, want %v", got, tt.wantManaged)
+			}
+			controller.RemovePodDeletionCost(pod)
+			if controller.HasPodDeletionCost(pod) || controller.IsManaged(pod) {
+				t.Errorf("RemovePodDeletionCost() left annotations %v", pod.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// FieldManager name of field manager used for server-side apply of PodDeletionCostAnnotation
+	FieldManager = "pod-deletion-cost-controller"
+)
+
+// LegacyFieldManager field manager of costs written by merge patches of controller releases before server-side
+// apply, derived by API server from controller binary name. Costs updated by it are treated as managed by controller,
+// so they are migrated on next write and cleaned up when workload is disabled. Migration is opt-in, empty value
+// disables it, as name like "manager" is shared by many operators
+var LegacyFieldManager = ""
+
+// PatchPodDeletionCost set PodDeletionCostAnnotation of Pod to value via server-side apply. Apply is conditioned
+// by resourceVersion of Pod, so racing writes end with Conflict error and reconcile is retried.
+// Callers decide whether cost may be written (see ForeignCostAnnotation), so ownership of annotation is forced.
+// Non-empty domain is recorded in DomainAnnotation. Pod is updated with applied value on success and write is counted
+// when ctx is created by withAssignments
+func PatchPodDeletionCost(ctx context.Context, c client.Client, pod *corev1.Pod, value int, domain string) error {
+	annotations := map[string]string{
+		PodDeletionCostAnnotation: strconv.Itoa(value),
+		ManagedByAnnotation:       ManagedByValue,
+	}
+	if domain != "" {
+		annotations[DomainAnnotation] = domain
+	}
+	ac := corev1ac.Pod(pod.Name, pod.Namespace).
+		WithResourceVersion(pod.ResourceVersion).
+		WithAnnotations(annotations)
+	if err := c.Apply(ctx, ac, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+	if n, ok := ctx.Value(assignmentsKey{}).(*int); ok {
+		*n++
+	}
+	ApplyPodDeletionCost(pod, value)
+	if domain != "" {
+		pod.Annotations[DomainAnnotation] = domain
+	} else {
+		delete(pod.Annotations, DomainAnnotation)
+	}
+	return nil
+}
+
+type assignmentsKey struct{}
+
+// withAssignments return context counting costs written by PatchPodDeletionCost into returned counter. Handlers
+// write costs of list copies of Pods as well, so the reconciled Pod alone does not show whether cost was assigned
+func withAssignments(ctx context.Context) (context.Context, *int) {
+	n := 0
+	return context.WithValue(ctx, assignmentsKey{}, &n), &n
+}
+
+// ownsPodDeletionCost decide by managedFields whether PodDeletionCostAnnotation is managed by controller.
+// Annotation is managed when owned by FieldManager, by manager owning ManagedByAnnotation as well, or updated by
+// LegacyFieldManager when set (costs written by merge patches before server-side apply was used). Known is false when
+// managedFields do not track annotation
+func ownsPodDeletionCost(pod *corev1.Pod) (owned bool, known bool) {
+	for _, entry := range pod.ManagedFields {
+		if !ownsAnnotation(entry.FieldsV1, PodDeletionCostAnnotation) {
+			continue
+		}
+		known = true
+		if entry.Manager == FieldManager || ownsAnnotation(entry.FieldsV1, ManagedByAnnotation) || isLegacy(entry) {
+			return true, true
+		}
+	}
+	return false, known
+}
+
+func isLegacy(entry metav1.ManagedFieldsEntry) bool {
+	return LegacyFieldManager != "" && entry.Manager == LegacyFieldManager && entry.Operation == metav1.ManagedFieldsOperationUpdate
+}
+
+func ownsAnnotation(fields *metav1.FieldsV1, key string) bool {
+	if fields == nil {
+		return false
+	}
+	set := map[string]map[string]map[string]json.RawMessage{}
+	if err := json.Unmarshal(fields.Raw, &set); err != nil {
+		return false
+	}
+	_, ok := set["f:metadata"]["f:annotations"]["f:"+key]
+	return ok
+}
@@ -0,0 +1,124 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func managedFieldsEntry(manager string, annotations ...string) v1.ManagedFieldsEntry {
+	raw := `{"f:metadata":{"f:annotations":{`
+	for i, a := range annotations {
+		if i > 0 {
+			raw += ","
+		}
+		raw += `"f:` + a + `":{}`
+	}
+	raw += `}}}`
+	return v1.ManagedFieldsEntry{
+		Manager:   manager,
+		Operation: v1.ManagedFieldsOperationApply,
+		FieldsV1:  &v1.FieldsV1{Raw: []byte(raw)},
+	}
+}
+
+func TestIsManaged(t *testing.T) {
+	legacy := managedFieldsEntry("manager", controller.PodDeletionCostAnnotation)
+	legacy.Operation = v1.ManagedFieldsOperationUpdate
+	tests := []struct {
+		name          string
+		legacyManager string
+		annotations   map[string]string
+		managedFields []v1.ManagedFieldsEntry
+		want          bool
+	}{
+		{
+			name:        "marker without managed fields",
+			annotations: map[string]string{controller.ManagedByAnnotation: controller.ManagedByValue},
+			want:        true,
+		},
+		{
+			name: "cost applied by controller",
+			managedFields: []v1.ManagedFieldsEntry{
+				managedFieldsEntry(controller.FieldManager, controller.PodDeletionCostAnnotation),
+			},
+			want: true,
+		},
+		{
+			name: "cost written together with marker",
+			managedFields: []v1.ManagedFieldsEntry{
+				managedFieldsEntry("manager", controller.PodDeletionCostAnnotation, controller.ManagedByAnnotation),
+			},
+			want: true,
+		},
+		{
+			name:          "cost updated by other operator with the same manager name",
+			managedFields: []v1.ManagedFieldsEntry{legacy},
+			want:          false,
+		},
+		{
+			name:          "cost written by controller before upgrade with migration enabled",
+			legacyManager: "manager",
+			managedFields: []v1.ManagedFieldsEntry{legacy},
+			want:          true,
+		},
+		{
+			name:          "cost applied by other tool with legacy manager name",
+			legacyManager: "manager",
+			managedFields: []v1.ManagedFieldsEntry{
+				managedFieldsEntry("manager", controller.PodDeletionCostAnnotation),
+			},
+			want: false,
+		},
+		{
+			name:        "cost taken over by other tool",
+			annotations: map[string]string{controller.ManagedByAnnotation: controller.ManagedByValue},
+			managedFields: []v1.ManagedFieldsEntry{
+				managedFieldsEntry(controller.FieldManager, controller.ManagedByAnnotation),
+				managedFieldsEntry("kubectl", controller.PodDeletionCostAnnotation),
+			},
+			want: false,
+		},
+		{
+			name: "cost set by user",
+			managedFields: []v1.ManagedFieldsEntry{
+				managedFieldsEntry("kubectl", controller.PodDeletionCostAnnotation),
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller.LegacyFieldManager = tt.legacyManager
+			t.Cleanup(func() { controller.LegacyFieldManager = "" })
+			pod := &corev1.Pod{ObjectMeta: v1.ObjectMeta{Annotations: tt.annotations, ManagedFields: tt.managedFields}}
+			require.Equal(t, tt.want, controller.IsManaged(pod))
+		})
+	}
+}
+
+func TestPatchPodDeletionCost(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "pod", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(pod).Build()
+	ctx := context.Background()
+
+	current := &corev1.Pod{}
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pod), current))
+
+	require.NoError(t, controller.PatchPodDeletionCost(ctx, c, current, 100, "zone-a"))
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(pod), current))
+	cost, ok := controller.GetPodDeletionCost(current)
+	require.True(t, ok)
+	require.Equal(t, 100, cost)
+	require.True(t, controller.IsManaged(current))
+	domain, ok := controller.GetDomain(current)
+	require.True(t, ok)
+	require.Equal(t, "zone-a", domain)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lablabs/pod-deletion-cost-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultPolicy cluster-wide default configuration applied to workloads in namespaces matching NamespaceSelector.
+// Workload annotations (including enabled "false" opt-out) and PodDeletionCostPolicy in namespace take precedence
+type DefaultPolicy struct {
+	// NamespaceSelector selects namespaces by labels. Empty selector matches all namespaces
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	v1alpha1.PodDeletionCostPolicySpec `json:",inline"`
+
+	namespaceSelector labels.Selector
+	selector          labels.Selector
+}
+
+// LoadDefaultPolicy load DefaultPolicy from YAML file
+func LoadDefaultPolicy(path string) (*DefaultPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read default policy: %w", err)
+	}
+	return ParseDefaultPolicy(data)
+}
+
+// ParseDefaultPolicy parse DefaultPolicy from YAML
+func ParseDefaultPolicy(data []byte) (*DefaultPolicy, error) {
+	p := &DefaultPolicy{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("unable to parse default policy: %w", err)
+	}
+	var err error
+	if p.namespaceSelector, err = asSelector(p.NamespaceSelector); err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	if p.selector, err = asSelector(p.Selector); err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+	return p, nil
+}
+
+// MatchesNamespace return true if namespace is selected by DefaultPolicy
+func (p *DefaultPolicy) MatchesNamespace(ns *corev1.Namespace) bool {
+	return p.namespaceSelector.Matches(labels.Set(ns.Labels))
+}
+
+// Matches return true if workload in namespace is selected by DefaultPolicy
+func (p *DefaultPolicy) Matches(ns *corev1.Namespace, obj metav1.Object) bool {
+	return p.MatchesNamespace(ns) && p.selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+func asSelector(s *metav1.LabelSelector) (labels.Selector, error) {
+	if s == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(s)
+}
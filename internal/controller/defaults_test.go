@@ -0,0 +1,141 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseDefaultPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "namespace selector and parameters",
+			data: `
+namespaceSelector:
+  matchLabels:
+    tier: prod
+algorithm: zone
+parameters:
+  spread-by: rack
+`,
+		},
+		{
+			name: "empty policy matches everything",
+			data: ``,
+		},
+		{
+			name:    "unknown field",
+			data:    `namespaceSelectr: {}`,
+			wantErr: true,
+		},
+		{
+			name: "invalid namespace selector",
+			data: `
+namespaceSelector:
+  matchExpressions:
+  - key: tier
+    operator: Bogus
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := controller.ParseDefaultPolicy([]byte(tt.data))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestResolveDefaultPolicy(t *testing.T) {
+	defaults, err := controller.ParseDefaultPolicy([]byte(`
+namespaceSelector:
+  matchLabels:
+    tier: prod
+algorithm: zone
+parameters:
+  spread-by: rack
+`))
+	require.NoError(t, err)
+	prod := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "prod", Labels: map[string]string{"tier": "prod"}}}
+	dev := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "dev", Labels: map[string]string{"tier": "dev"}}}
+	policy := newPolicy("policy", time.Hour, nil)
+	policy.Namespace = prod.Name
+	policy.Spec.Selector = &v1.LabelSelector{MatchLabels: map[string]string{"app": "governed"}}
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(prod, dev, &policy).Build()
+	r := controller.NewWorkloadResolver(c).WithDefaultPolicy(defaults)
+
+	tests := []struct {
+		name        string
+		namespace   string
+		labels      map[string]string
+		annotations map[string]string
+		wantEnabled bool
+		wantPolicy  string
+		wantSpread  string
+	}{
+		{
+			name:        "workload in selected namespace is enabled",
+			namespace:   prod.Name,
+			wantEnabled: true,
+			wantSpread:  "rack",
+		},
+		{
+			name:        "workload in other namespace is not enabled",
+			namespace:   dev.Name,
+			wantEnabled: false,
+		},
+		{
+			name:        "opt-out annotation has precedence",
+			namespace:   prod.Name,
+			annotations: map[string]string{controller.EnableAnnotation: "false"},
+			wantEnabled: false,
+		},
+		{
+			name:        "namespaced policy has precedence",
+			namespace:   prod.Name,
+			labels:      map[string]string{"app": "governed"},
+			wantEnabled: true,
+			wantPolicy:  "policy",
+			wantSpread:  "rack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{
+					Name:        "app",
+					Namespace:   tt.namespace,
+					Labels:      tt.labels,
+					Annotations: tt.annotations,
+				},
+			}
+			w, err := r.ResolvePolicy(context.Background(), module.FromDeployment(dep))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantEnabled, controller.IsEnabled(w))
+			require.Equal(t, tt.wantPolicy, w.Policy)
+			if tt.wantSpread != "" {
+				require.Equal(t, "zone", controller.GetType(w))
+				require.Equal(t, tt.wantSpread, w.GetAnnotations()[controller.AnnotationPrefix+"spread-by"])
+			}
+		})
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,8 +21,10 @@ import (
 const (
 	//PodToRSIndex index name for Pod to Rs
 	PodToRSIndex = "spec.rsUID"
-	// RsToDeploymentIndex index name for Rs to Deployment
+	// RsToDeploymentIndex index name for Rs to Deployment or other configured owner
 	RsToDeploymentIndex = "spec.deploymentUID"
+	// PodToNodeIndex index name for Pod to Node it is bound to
+	PodToNodeIndex = "spec.nodeName"
 )
 
 // createPodToRSIndex create index for mapping Pod to ReplicaSet owner reference UID
@@ -34,58 +40,195 @@ func createPodToRSIndex(mgr ctrl.Manager) error {
 	})
 }
 
-// createRsToDeploymentIndex create index for mapping ReplicaSet owner reference UID
-func createRsToDeploymentIndex(mgr ctrl.Manager) error {
+// createPodToNodeIndex create index for mapping Pod to name of Node it is bound to
+func createPodToNodeIndex(mgr ctrl.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, PodToNodeIndex, func(obj client.Object) []string {
+		pod := obj.(*corev1.Pod)
+		if pod.Spec.NodeName == "" {
+			return nil
+		}
+		return []string{pod.Spec.NodeName}
+	})
+}
+
+// createRsToDeploymentIndex create index for mapping ReplicaSet owner reference UID of Deployment or configured owner kinds
+func createRsToDeploymentIndex(mgr ctrl.Manager, ownerKinds []schema.GroupVersionKind) error {
 	return mgr.GetFieldIndexer().IndexField(context.Background(), &v1.ReplicaSet{}, RsToDeploymentIndex, func(obj client.Object) []string {
 		rs := obj.(*v1.ReplicaSet)
 		for _, owner := range rs.OwnerReferences {
 			if owner.Kind == "Deployment" {
 				return []string{string(owner.UID)}
 			}
+			if _, ok := findOwnerKind(owner, ownerKinds); ok {
+				return []string{string(owner.UID)}
+			}
 		}
 		return nil
 	})
 }
 
-func mapDeploymentToPodReconcileFunc(c client.Client) handler.MapFunc {
+func mapDeploymentToPodReconcileFunc(r *WorkloadResolver) handler.MapFunc {
 	return func(ctx context.Context, object client.Object) []reconcile.Request {
-		log := logr.FromContext(ctx)
 		dep := object.(*v1.Deployment)
-		if !IsEnabled(dep) {
+		return r.mapWorkloadToPodRequests(ctx, module.FromDeployment(dep))
+	}
+}
+
+func mapReplicaSetToPodReconcileFunc(r *WorkloadResolver) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		rs := object.(*v1.ReplicaSet)
+		if metav1.GetControllerOf(rs) != nil {
 			return nil
 		}
-		rsList := &v1.ReplicaSetList{}
-		if err := c.List(ctx, rsList, client.MatchingFields{RsToDeploymentIndex: string(dep.UID)}); err != nil {
-			log.Error(err, "unable to list ReplicaSets")
+		return r.mapWorkloadToPodRequests(ctx, module.FromReplicaSet(rs))
+	}
+}
+
+func mapUnstructuredToPodReconcileFunc(r *WorkloadResolver) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		log := logr.FromContext(ctx)
+		w, err := module.FromUnstructured(object.(*unstructured.Unstructured))
+		if err != nil {
+			log.Error(err, "unable to read workload")
 			return nil
 		}
+		return r.mapWorkloadToPodRequests(ctx, w)
+	}
+}
 
+func mapPolicyToPodReconcileFunc(r *WorkloadResolver) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		log := logr.FromContext(ctx)
+		workloads, err := r.ListWorkloads(ctx, object.GetNamespace())
+		if err != nil {
+			log.Error(err, "unable to list workloads")
+			return nil
+		}
 		reqs := make([]reconcile.Request, 0)
-		for _, rs := range rsList.Items {
-			podList := &corev1.PodList{}
-			if err := c.List(ctx, podList, client.MatchingFields{PodToRSIndex: string(rs.UID)}); err != nil {
-				log.Error(err, "unable to list Pods")
-				continue
-			}
-			for _, pod := range podList.Items {
-				if !IsAccepted(&pod) {
-					continue
-				}
-				if HasPodDeletionCost(&pod) {
-					continue
-				}
-				reqs = append(reqs, reconcile.Request{
-					NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
-				})
-			}
+		for _, w := range workloads {
+			reqs = append(reqs, r.mapWorkloadToPodRequests(ctx, w)...)
 		}
+		return reqs
+	}
+}
 
+func mapNamespaceToPodReconcileFunc(r *WorkloadResolver) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		log := logr.FromContext(ctx)
+		workloads, err := r.ListWorkloads(ctx, object.GetName())
+		if err != nil {
+			log.Error(err, "unable to list workloads")
+			return nil
+		}
+		reqs := make([]reconcile.Request, 0)
+		for _, w := range workloads {
+			reqs = append(reqs, r.mapWorkloadToPodRequests(ctx, w)...)
+		}
 		return reqs
 	}
 }
 
-// GetDeployment return deployment associated with Pod
-func GetDeployment(ctx context.Context, c client.Client, pod *corev1.Pod) (*v1.Deployment, error) {
+func (r *WorkloadResolver) mapWorkloadToPodRequests(ctx context.Context, w *module.Workload) []reconcile.Request {
+	log := logr.FromContext(ctx)
+	w, err := r.ResolvePolicy(ctx, w)
+	if err != nil {
+		log.Error(err, "unable to resolve policy")
+		return nil
+	}
+	enabled := IsEnabled(w)
+	podList := &corev1.PodList{}
+	if err := ListWorkloadPods(ctx, r.client, w, podList); err != nil {
+		log.Error(err, "unable to list Pods")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0)
+	for _, pod := range podList.Items {
+		// Pods of disabled workload are reconciled only to clean up pod-deletion-cost managed by controller
+		if !enabled && !IsManaged(&pod) {
+			continue
+		}
+		if enabled && (!IsAccepted(&pod) || !NeedsCost(&pod, w)) {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
+		})
+	}
+	return reqs
+}
+
+// ListWorkloadPods list all Pods of Workload across all its ReplicaSets
+func ListWorkloadPods(ctx context.Context, c client.Client, w *module.Workload, list *corev1.PodList) error {
+	if w.Kind == module.KindReplicaSet {
+		return c.List(ctx, list,
+			client.InNamespace(w.GetNamespace()),
+			client.MatchingFields{PodToRSIndex: string(w.GetUID())},
+		)
+	}
+	rsList := &v1.ReplicaSetList{}
+	err := c.List(ctx, rsList,
+		client.InNamespace(w.GetNamespace()),
+		client.MatchingFields{RsToDeploymentIndex: string(w.GetUID())},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to list ReplicaSets: %w", err)
+	}
+	for _, rs := range rsList.Items {
+		podList := &corev1.PodList{}
+		err := c.List(ctx, podList,
+			client.InNamespace(rs.Namespace),
+			client.MatchingFields{PodToRSIndex: string(rs.UID)},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to list Pods: %w", err)
+		}
+		list.Items = append(list.Items, podList.Items...)
+	}
+	return nil
+}
+
+// NewWorkloadResolver create new WorkloadResolver. ReplicaSets owned by ownerKinds are resolved via unstructured client
+func NewWorkloadResolver(c client.Client, ownerKinds ...schema.GroupVersionKind) *WorkloadResolver {
+	return &WorkloadResolver{
+		client:     c,
+		ownerKinds: ownerKinds,
+	}
+}
+
+// WorkloadResolver finds Workload associated with Pod
+type WorkloadResolver struct {
+	client        client.Client
+	ownerKinds    []schema.GroupVersionKind
+	defaultPolicy *DefaultPolicy
+	// withoutPolicies disables lookup of PodDeletionCostPolicy, e.g. when its CRD is not installed
+	withoutPolicies bool
+}
+
+// WithDefaultPolicy sets cluster default policy applied to workloads not matched by any policy
+func (r *WorkloadResolver) WithDefaultPolicy(p *DefaultPolicy) *WorkloadResolver {
+	r.defaultPolicy = p
+	return r
+}
+
+// WithoutPolicies disables lookup of PodDeletionCostPolicy, workloads are configured by annotations and cluster
+// default policy only
+func (r *WorkloadResolver) WithoutPolicies() *WorkloadResolver {
+	r.withoutPolicies = true
+	return r
+}
+
+// GetWorkload return Workload associated with Pod. It is Deployment or object of configured owner kind owning Pod ReplicaSet,
+// or the ReplicaSet itself when it has no owner. Workload without own configuration is configured by matching policy
+func (r *WorkloadResolver) GetWorkload(ctx context.Context, pod *corev1.Pod) (*module.Workload, error) {
+	w, err := r.getOwner(ctx, pod)
+	if err != nil {
+		return nil, err
+	}
+	return r.ResolvePolicy(ctx, w)
+}
+
+func (r *WorkloadResolver) getOwner(ctx context.Context, pod *corev1.Pod) (*module.Workload, error) {
 	var rsName string
 	for _, owner := range pod.OwnerReferences {
 		if owner.Kind == "ReplicaSet" {
@@ -98,26 +241,66 @@ func GetDeployment(ctx context.Context, c client.Client, pod *corev1.Pod) (*v1.D
 	}
 
 	rs := &v1.ReplicaSet{}
-	if err := c.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: rsName}, rs); err != nil {
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: rsName}, rs); err != nil {
 		return nil, fmt.Errorf("get replicaset %s/%s: %w", pod.Namespace, rsName, err)
 	}
 
-	// 2) Find owning Deployment
-	var deployName string
-	for _, owner := range rs.OwnerReferences {
-		if owner.Kind == "Deployment" {
-			deployName = owner.Name
-			break
+	// 2) Find owning workload
+	owner := metav1.GetControllerOf(rs)
+	if owner == nil {
+		return module.FromReplicaSet(rs), nil
+	}
+	if owner.Kind == "Deployment" {
+		deploy := &v1.Deployment{}
+		if err := r.client.Get(ctx, types.NamespacedName{Namespace: rs.Namespace, Name: owner.Name}, deploy); err != nil {
+			return nil, fmt.Errorf("get deployment %s/%s: %w", rs.Namespace, owner.Name, err)
 		}
+		return module.FromDeployment(deploy), nil
 	}
-	if deployName == "" {
-		return nil, fmt.Errorf("replicaset %s/%s has no owning Deployment", rs.Namespace, rs.Name)
+	kind, ok := findOwnerKind(*owner, r.ownerKinds)
+	if !ok {
+		return nil, fmt.Errorf("replicaset %s/%s is owned by unsupported %s %s", rs.Namespace, rs.Name, owner.APIVersion, owner.Kind)
 	}
-
-	deploy := &v1.Deployment{}
-	if err := c.Get(ctx, types.NamespacedName{Namespace: rs.Namespace, Name: deployName}, deploy); err != nil {
-		return nil, fmt.Errorf("get deployment %s/%s: %w", rs.Namespace, deployName, err)
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(kind)
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: rs.Namespace, Name: owner.Name}, obj); err != nil {
+		return nil, fmt.Errorf("get %s %s/%s: %w", kind.Kind, rs.Namespace, owner.Name, err)
 	}
+	return module.FromUnstructured(obj)
+}
 
-	return deploy, nil
+// ListWorkloads list all workloads in namespace: Deployments, ReplicaSets without owner and objects of configured owner kinds
+func (r *WorkloadResolver) ListWorkloads(ctx context.Context, namespace string) ([]*module.Workload, error) {
+	workloads := make([]*module.Workload, 0)
+	deployments := &v1.DeploymentList{}
+	if err := r.client.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list Deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, module.FromDeployment(&deployments.Items[i]))
+	}
+	replicaSets := &v1.ReplicaSetList{}
+	if err := r.client.List(ctx, replicaSets, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list ReplicaSets: %w", err)
+	}
+	for i := range replicaSets.Items {
+		if metav1.GetControllerOf(&replicaSets.Items[i]) == nil {
+			workloads = append(workloads, module.FromReplicaSet(&replicaSets.Items[i]))
+		}
+	}
+	for _, kind := range r.ownerKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(kind.GroupVersion().WithKind(kind.Kind + "List"))
+		if err := r.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("unable to list %s: %w", kind.Kind, err)
+		}
+		for i := range list.Items {
+			w, err := module.FromUnstructured(&list.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			workloads = append(workloads, w)
+		}
+	}
+	return workloads, nil
 }
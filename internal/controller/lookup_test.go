@@ -0,0 +1,133 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/test/utils"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetWorkload(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	ownedRS := &appsv1.ReplicaSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "app-1",
+			Namespace: "default",
+			OwnerReferences: []v1.OwnerReference{
+				{Kind: "Deployment", Name: deploy.Name, UID: deploy.UID, Controller: utils.Pointer(true)},
+			},
+		},
+	}
+	bareRS := &appsv1.ReplicaSet{
+		ObjectMeta: v1.ObjectMeta{Name: "bare", Namespace: "default"},
+	}
+	podOf := func(rs *appsv1.ReplicaSet) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      rs.Name + "-pod",
+				Namespace: "default",
+				OwnerReferences: []v1.OwnerReference{
+					{Kind: "ReplicaSet", Name: rs.Name, Controller: utils.Pointer(true)},
+				},
+			},
+		}
+	}
+	rollout := &unstructured.Unstructured{}
+	rollout.SetGroupVersionKind(controller.RolloutGVK)
+	rollout.SetName("rollout")
+	rollout.SetNamespace("default")
+	err := unstructured.SetNestedField(rollout.Object, map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "rollout"}},
+	}, "spec", "template")
+	require.NoError(t, err)
+	rolloutRS := &appsv1.ReplicaSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "rollout-1",
+			Namespace: "default",
+			OwnerReferences: []v1.OwnerReference{
+				{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "rollout", Controller: utils.Pointer(true)},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(deploy, ownedRS, bareRS, rollout, rolloutRS).Build()
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		wantKind string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "pod of deployment",
+			pod:      podOf(ownedRS),
+			wantKind: module.KindDeployment,
+			wantName: deploy.Name,
+		},
+		{
+			name:     "pod of bare replicaset",
+			pod:      podOf(bareRS),
+			wantKind: module.KindReplicaSet,
+			wantName: bareRS.Name,
+		},
+		{
+			name:     "pod of rollout",
+			pod:      podOf(rolloutRS),
+			wantKind: controller.RolloutGVK.Kind,
+			wantName: "rollout",
+		},
+		{
+			name:    "pod without replicaset",
+			pod:     &corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: "single", Namespace: "default"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := controller.NewWorkloadResolver(c, controller.RolloutGVK).GetWorkload(context.Background(), tt.pod)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantKind, w.Kind)
+			require.Equal(t, tt.wantName, w.GetName())
+		})
+	}
+}
+
+func TestGetWorkloadRolloutsDisabled(t *testing.T) {
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "rollout-1",
+			Namespace: "default",
+			OwnerReferences: []v1.OwnerReference{
+				{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "rollout", Controller: utils.Pointer(true)},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "default",
+			OwnerReferences: []v1.OwnerReference{
+				{Kind: "ReplicaSet", Name: rs.Name, Controller: utils.Pointer(true)},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(rs).Build()
+
+	_, err := controller.NewWorkloadResolver(c).GetWorkload(context.Background(), pod)
+	require.Error(t, err)
+}
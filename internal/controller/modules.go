@@ -3,11 +3,13 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
-	v2 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 // NewModuleManager creates new Manager
@@ -34,16 +36,87 @@ func (m *Manager) AddModule(module module.Handler) error {
 	return nil
 }
 
-// Handle accepts Pod and Deployment and update it according to type
-func (m *Manager) Handle(ctx context.Context, log logr.Logger, pod *v1.Pod, dep *v2.Deployment) error {
-	algType := GetType(dep)
-	if !IsEnabled(dep) {
+// Handle accepts Pod and Workload and update it according to type
+func (m *Manager) Handle(ctx context.Context, log logr.Logger, pod *v1.Pod, w *module.Workload) error {
+	algType := GetType(w)
+	if !IsEnabled(w) {
 		return nil
 	}
 	h, exist := m.modules[algType]
 	if !exist {
-		log.V(3).WithValues("deployment", dep.Name, TypeAnnotation, algType).Info("handler not found")
+		log.V(3).WithValues("workload", w.GetName(), TypeAnnotation, algType).Info("handler not found")
 		return nil
 	}
-	return h.Handle(ctx, log, pod, dep)
+	return h.Handle(ctx, log, pod, w)
+}
+
+// InitialCost return initial pod-deletion-cost of Pod being created. False is returned when workload
+// is not enabled or its module does not implement module.Initializer
+func (m *Manager) InitialCost(ctx context.Context, log logr.Logger, pod *v1.Pod, w *module.Workload) (int, bool, error) {
+	if !IsEnabled(w) {
+		return 0, false, nil
+	}
+	h, exist := m.modules[GetType(w)]
+	if !exist {
+		return 0, false, nil
+	}
+	i, ok := h.(module.Initializer)
+	if !ok {
+		return 0, false, nil
+	}
+	cost, err := i.InitialCost(ctx, log, pod, w)
+	if err != nil {
+		return 0, false, err
+	}
+	return cost, true, nil
+}
+
+// RequeueAfter return delay after which Pod is reconciled again. Zero is returned when workload is not enabled
+// or its module does not implement module.Requeuer
+func (m *Manager) RequeueAfter(pod *v1.Pod, w *module.Workload) time.Duration {
+	if !IsEnabled(w) {
+		return 0
+	}
+	h, exist := m.modules[GetType(w)]
+	if !exist {
+		return 0
+	}
+	r, ok := h.(module.Requeuer)
+	if !ok {
+		return 0
+	}
+	return r.RequeueAfter(pod, w)
+}
+
+// Validate validates configuration annotations of workload by module of its type. Workload with type
+// of no registered module is rejected
+func (m *Manager) Validate(ctx context.Context, w *module.Workload) ([]string, field.ErrorList) {
+	algType := GetType(w)
+	h, exist := m.modules[algType]
+	if !exist {
+		path := field.NewPath("metadata", "annotations").Key(TypeAnnotation)
+		return nil, field.ErrorList{field.NotSupported(path, algType, m.types())}
+	}
+	v, ok := h.(module.Validator)
+	if !ok {
+		return nil, nil
+	}
+	return v.Validate(ctx, w)
+}
+
+func (m *Manager) types() []string {
+	types := make([]string, 0, len(m.modules))
+	for t := range m.modules {
+		if t != "" {
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// HasType return true if module accepting algorithm type is registered
+func (m *Manager) HasType(algType string) bool {
+	_, exist := m.modules[algType]
+	return exist
 }
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DisruptionTaints taints added to Nodes being removed by Karpenter or cluster autoscaler. Pods on Node with any
+// of them are draining even if they tolerate the taint
+var DisruptionTaints = []string{
+	"karpenter.sh/disruption",
+	"ToBeDeletedByClusterAutoscaler",
+}
+
+// IsDraining return true if Pod runs on Node which is cordoned (spec.unschedulable), carries disruption taint,
+// or NoSchedule/NoExecute taint not tolerated by Pod. Taints tolerated by Pod, e.g. of dedicated node pools, are ignored
+func IsDraining(node *corev1.Node, pod *corev1.Pod) bool {
+	if node == nil {
+		return false
+	}
+	if node.Spec.Unschedulable {
+		return true
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if slices.Contains(DisruptionTaints, taint.Key) {
+			return true
+		}
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		if !slices.ContainsFunc(pod.Spec.Tolerations, func(t corev1.Toleration) bool {
+			return t.ToleratesTaint(taint)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// NodePredicate accepts updates of Nodes with changed spec.unschedulable, taints or labels, e.g. topology labels
+// added late or changed, and changed allocatable resources, which Node occupancy is relative to
+func NodePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+				!equality.Semantic.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+				!equality.Semantic.DeepEqual(oldNode.Labels, newNode.Labels) ||
+				!equality.Semantic.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable)
+		},
+	}
+}
+
+// mapNodeToPodReconcileFunc enqueue Pods bound to Node, so they are re-ranked when Node starts or stops draining
+// or its labels change
+func mapNodeToPodReconcileFunc(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		return podsOnNode(ctx, c, object.GetName(), func(pod *corev1.Pod) bool {
+			return HasPodDeletionCost(pod) || IsAccepted(pod)
+		})
+	}
+}
+
+// NeighbourPredicate accepts Pods added to or removed from Node: bound, finished or deleted. Occupancy of Node
+// changes for Pods of all workloads running there
+func NeighbourPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isBound(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isBound(e.Object) },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return oldPod.Spec.NodeName != newPod.Spec.NodeName || isFinished(oldPod) != isFinished(newPod)
+		},
+	}
+}
+
+// mapPodToNeighbourReconcileFunc enqueue Pods with cost managed by controller sharing Node with Pod, so rankings
+// depending on occupancy of Node are recomputed when Pods of other workloads come and go
+func mapPodToNeighbourReconcileFunc(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		pod, ok := object.(*corev1.Pod)
+		if !ok || pod.Spec.NodeName == "" {
+			return nil
+		}
+		return podsOnNode(ctx, c, pod.Spec.NodeName, func(p *corev1.Pod) bool {
+			return p.UID != pod.UID && IsManaged(p)
+		})
+	}
+}
+
+// podsOnNode return requests of Pods bound to Node accepted by keep
+func podsOnNode(ctx context.Context, c client.Client, nodeName string, keep func(pod *corev1.Pod) bool) []reconcile.Request {
+	log := logr.FromContext(ctx)
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.MatchingFields{PodToNodeIndex: nodeName}); err != nil {
+		log.Error(err, "unable to list Pods")
+		return nil
+	}
+	reqs := make([]reconcile.Request, 0, len(podList.Items))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !keep(pod) {
+			continue
+		}
+		reqs = append(reqs, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name},
+		})
+	}
+	return reqs
+}
+
+func isBound(obj client.Object) bool {
+	pod, ok := obj.(*corev1.Pod)
+	return ok && pod.Spec.NodeName != ""
+}
+
+func isFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
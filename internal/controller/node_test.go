@@ -0,0 +1,109 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestIsDraining(t *testing.T) {
+	dedicated := corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}
+	tests := []struct {
+		name        string
+		node        *corev1.Node
+		tolerations []corev1.Toleration
+		want        bool
+	}{
+		{
+			name: "no node",
+			node: nil,
+			want: false,
+		},
+		{
+			name: "healthy node",
+			node: &corev1.Node{},
+			want: false,
+		},
+		{
+			name: "cordoned node",
+			node: &corev1.Node{Spec: corev1.NodeSpec{Unschedulable: true}},
+			want: true,
+		},
+		{
+			name: "not tolerated NoExecute taint",
+			node: &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "node.kubernetes.io/out-of-service", Effect: corev1.TaintEffectNoExecute},
+			}}},
+			want: true,
+		},
+		{
+			name:        "tolerated taint of dedicated node pool",
+			node:        &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{dedicated}}},
+			tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu"}},
+			want:        false,
+		},
+		{
+			name: "PreferNoSchedule taint",
+			node: &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "soft", Effect: corev1.TaintEffectPreferNoSchedule},
+			}}},
+			want: false,
+		},
+		{
+			name: "tolerated disruption taint",
+			node: &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "karpenter.sh/disruption", Value: "disrupting", Effect: corev1.TaintEffectNoSchedule},
+			}}},
+			tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			want:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Tolerations: tt.tolerations}}
+			require.Equal(t, tt.want, controller.IsDraining(tt.node, pod))
+		})
+	}
+}
+
+func TestNodePredicate(t *testing.T) {
+	pred := controller.NodePredicate()
+	healthy := &corev1.Node{}
+	cordoned := &corev1.Node{Spec: corev1.NodeSpec{Unschedulable: true}}
+	tainted := &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "k", Effect: corev1.TaintEffectNoSchedule}}}}
+	labeled := healthy.DeepCopy()
+	labeled.Labels = map[string]string{"k": "v"}
+
+	resized := healthy.DeepCopy()
+	resized.Status.Allocatable = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: healthy, ObjectNew: cordoned}))
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: tainted, ObjectNew: healthy}))
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: healthy, ObjectNew: labeled}))
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: healthy, ObjectNew: resized}))
+	require.False(t, pred.Update(event.UpdateEvent{ObjectOld: healthy, ObjectNew: healthy.DeepCopy()}))
+	require.False(t, pred.Create(event.CreateEvent{Object: cordoned}))
+}
+
+func TestNeighbourPredicate(t *testing.T) {
+	pred := controller.NeighbourPredicate()
+	pending := &corev1.Pod{}
+	bound := &corev1.Pod{Spec: corev1.PodSpec{NodeName: "node-a"}}
+	running := bound.DeepCopy()
+	running.Status.Phase = corev1.PodRunning
+	succeeded := bound.DeepCopy()
+	succeeded.Status.Phase = corev1.PodSucceeded
+
+	require.True(t, pred.Create(event.CreateEvent{Object: bound}))
+	require.False(t, pred.Create(event.CreateEvent{Object: pending}))
+	require.True(t, pred.Delete(event.DeleteEvent{Object: bound}))
+	require.False(t, pred.Delete(event.DeleteEvent{Object: pending}))
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: pending, ObjectNew: bound}), "pod bound to node")
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: succeeded}), "pod finished")
+	require.False(t, pred.Update(event.UpdateEvent{ObjectOld: bound, ObjectNew: running}))
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RolloutGVK GroupVersionKind of Argo Rollout
+var RolloutGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+
+// ParseOwnerKind parse owner kind in format group/version/Kind, e.g. argoproj.io/v1alpha1/Rollout
+func ParseOwnerKind(value string) (schema.GroupVersionKind, error) {
+	i := strings.LastIndex(value, "/")
+	if i <= 0 || i == len(value)-1 {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid owner kind %q, expected group/version/Kind", value)
+	}
+	gv, err := schema.ParseGroupVersion(value[:i])
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid owner kind %q: %w", value, err)
+	}
+	if gv.Group == "" || gv.Version == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid owner kind %q, expected group/version/Kind", value)
+	}
+	return gv.WithKind(value[i+1:]), nil
+}
+
+// findOwnerKind return configured owner kind matching owner reference. Version is not compared,
+// so ReplicaSets created by older API version of owner are matched as well
+func findOwnerKind(owner metav1.OwnerReference, kinds []schema.GroupVersionKind) (schema.GroupVersionKind, bool) {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, false
+	}
+	for _, kind := range kinds {
+		if kind.Group == gv.Group && kind.Kind == owner.Kind {
+			return kind, true
+		}
+	}
+	return schema.GroupVersionKind{}, false
+}
@@ -0,0 +1,56 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseOwnerKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    schema.GroupVersionKind
+		wantErr bool
+	}{
+		{
+			name:  "argo rollout",
+			value: "argoproj.io/v1alpha1/Rollout",
+			want:  controller.RolloutGVK,
+		},
+		{
+			name:  "custom operator",
+			value: "apps.example.com/v1/MyApp",
+			want:  schema.GroupVersionKind{Group: "apps.example.com", Version: "v1", Kind: "MyApp"},
+		},
+		{
+			name:    "missing kind",
+			value:   "argoproj.io/v1alpha1/",
+			wantErr: true,
+		},
+		{
+			name:    "missing group",
+			value:   "v1/Pod",
+			wantErr: true,
+		},
+		{
+			name:    "kind only",
+			value:   "Rollout",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := controller.ParseOwnerKind(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
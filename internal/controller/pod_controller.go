@@ -18,15 +18,20 @@ package controller
 
 import (
 	"context"
+	"time"
 
+	"github.com/lablabs/pod-deletion-cost-controller/api/v1alpha1"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 // PodReconciler reconciles a Pod object
@@ -34,11 +39,29 @@ type PodReconciler struct {
 	client.Client
 	Scheme  *runtime.Scheme
 	Manager *Manager
+	// OwnerKinds additional kinds owning ReplicaSets, e.g. Argo Rollout, resolved via unstructured client
+	OwnerKinds []schema.GroupVersionKind
+	// DefaultPolicy cluster-wide default configuration of workloads not configured by annotations or policies
+	DefaultPolicy *DefaultPolicy
+	// WatchStatus reconciles Pods on health changes in status, e.g. container restarts or readiness transitions
+	WatchStatus bool
+	// StatusDebounce delays reconcile of health changes, changes of Pod within delay are merged into one reconcile
+	StatusDebounce time.Duration
+	// DisablePolicies neither watches nor resolves PodDeletionCostPolicy, e.g. when its CRD is not installed
+	DisablePolicies bool
+	// WatchNeighbours reconciles Pods on changes of other Pods of their Node, for rankings by Node occupancy
+	WatchNeighbours bool
+
+	resolver *WorkloadResolver
 }
 
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=pods/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=pod-deletion-cost.lablabs.io,resources=poddeletioncostpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=pod-deletion-cost.lablabs.io,resources=poddeletioncostpolicies/status,verbs=get;update;patch
 
 // Reconcile is called for each Pod event
 func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -49,21 +72,43 @@ func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	dep, err := GetDeployment(ctx, r.Client, pod)
+	w, err := r.resolver.GetWorkload(ctx, pod)
 	if err != nil {
 		log.V(2).Info(err.Error())
 		return ctrl.Result{}, nil
 	}
-	if !IsEnabled(dep) {
-		log.V(2).WithValues("deployment", dep.Name).Info("not annotate")
+	log = log.WithValues("workload", w.GetName(), "kind", w.Kind)
+	if !IsEnabled(w) {
+		if IsManaged(pod) {
+			log.Info("remove pod-deletion-cost of disabled workload")
+			return ctrl.Result{}, r.cleanup(ctx, pod)
+		}
+		log.V(2).Info("not annotate")
 		return ctrl.Result{}, nil
 	}
-	log.V(2).WithValues("deployment", dep.Name).Info("found")
-	err = r.Manager.Handle(ctx, log, pod, dep)
+	log.V(2).Info("found")
+	handleCtx, assignments := withAssignments(ctx)
+	err = r.Manager.Handle(handleCtx, log, pod, w)
+	if w.Policy != "" {
+		assigned := err == nil && *assignments > 0
+		failed := client.IgnoreNotFound(err) != nil
+		if assigned || failed {
+			if recErr := recordAssignment(ctx, r.Client, w, err); recErr != nil {
+				log.V(2).WithValues("policy", w.Policy).Info("unable to record assignment", "error", recErr.Error())
+			}
+		}
+	}
 	if err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.Manager.RequeueAfter(pod, w)}, nil
+}
+
+// cleanup remove pod-deletion-cost managed by controller from Pod of disabled workload
+func (r *PodReconciler) cleanup(ctx context.Context, pod *corev1.Pod) error {
+	patch := client.MergeFromWithOptions(pod.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	RemovePodDeletionCost(pod)
+	return client.IgnoreNotFound(r.Patch(ctx, pod, patch, client.FieldOwner(FieldManager)))
 }
 
 // SetupWithManager configure PodReconciler
@@ -71,13 +116,48 @@ func (r *PodReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := createPodToRSIndex(mgr); err != nil {
 		return err
 	}
-	if err := createRsToDeploymentIndex(mgr); err != nil {
+	if err := createRsToDeploymentIndex(mgr, r.OwnerKinds); err != nil {
 		return err
 	}
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Pod{}, builder.WithPredicates(PodPredicate())).
-		Watches(&v1.ReplicaSet{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &v1.Deployment{}, handler.OnlyControllerOwner())).
-		Watches(&v1.Deployment{}, handler.EnqueueRequestsFromMapFunc(mapDeploymentToPodReconcileFunc(r.Client)), builder.WithPredicates(DeploymentPredicate())).
-		Watches(&corev1.Node{}, handler.Funcs{}).
-		Complete(r)
+	if err := createPodToNodeIndex(mgr); err != nil {
+		return err
+	}
+	r.resolver = NewWorkloadResolver(r.Client, r.OwnerKinds...).WithDefaultPolicy(r.DefaultPolicy)
+	if r.DisablePolicies {
+		r.resolver.WithoutPolicies()
+	}
+	podPredicates := []predicate.Predicate{PodPredicate()}
+	if r.WatchStatus {
+		// health changes of Pods with cost are reconciled by debounced status watch only
+		podPredicates = append(podPredicates, IgnoreStatusPredicate())
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}, builder.WithPredicates(podPredicates...)).
+		Watches(&v1.ReplicaSet{}, handler.EnqueueRequestsFromMapFunc(mapReplicaSetToPodReconcileFunc(r.resolver)),
+			builder.WithPredicates(predicate.Or(ReplicaSetPredicate(), DisabledPredicate(), predicate.LabelChangedPredicate{}))).
+		Watches(&v1.Deployment{}, handler.EnqueueRequestsFromMapFunc(mapDeploymentToPodReconcileFunc(r.resolver)),
+			builder.WithPredicates(predicate.Or(DeploymentPredicate(), DisabledPredicate(), predicate.LabelChangedPredicate{}))).
+		Watches(&corev1.Node{}, handler.EnqueueRequestsFromMapFunc(mapNodeToPodReconcileFunc(r.Client)),
+			builder.WithPredicates(NodePredicate()))
+	if !r.DisablePolicies {
+		b = b.Watches(&v1alpha1.PodDeletionCostPolicy{}, handler.EnqueueRequestsFromMapFunc(mapPolicyToPodReconcileFunc(r.resolver)))
+	}
+	if r.WatchStatus {
+		b = b.Watches(&corev1.Pod{}, enqueueAfter(r.StatusDebounce), builder.WithPredicates(StatusPredicate()))
+	}
+	if r.WatchNeighbours {
+		b = b.Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(mapPodToNeighbourReconcileFunc(r.Client)),
+			builder.WithPredicates(NeighbourPredicate()))
+	}
+	if r.DefaultPolicy != nil {
+		b = b.Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(mapNamespaceToPodReconcileFunc(r.resolver)),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+	for _, kind := range r.OwnerKinds {
+		owner := &unstructured.Unstructured{}
+		owner.SetGroupVersionKind(kind)
+		b = b.Watches(owner, handler.EnqueueRequestsFromMapFunc(mapUnstructuredToPodReconcileFunc(r.resolver)),
+			builder.WithPredicates(predicate.Or(OwnerPredicate(kind), DisabledPredicate(), predicate.LabelChangedPredicate{})))
+	}
+	return b.Complete(r)
 }
@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller_test
+
+import (
+	"context"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/test/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Pod mutating webhook", func() {
+	var (
+		ctx = context.Background()
+	)
+	Context("when a Pod of enabled Deployment is created through the API server", func() {
+		It("assigns provisional pod-deletion-cost to the Pod", func() {
+			By("Creating Deployment and RS")
+			labels := map[string]string{"app": "webhook"}
+			template := corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "nginx", Image: "nginx:latest"}},
+				},
+			}
+			deploy := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{
+					Name:        "webhook-deploy",
+					Namespace:   "default",
+					Labels:      labels,
+					Annotations: map[string]string{controller.EnableAnnotation: "true"},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: utils.Pointer[int32](1),
+					Selector: &v1.LabelSelector{MatchLabels: labels},
+					Template: template,
+				},
+			}
+			Expect(k8sClient.Create(ctx, deploy)).To(Succeed())
+			rs := &appsv1.ReplicaSet{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "webhook-deploy",
+					Namespace: "default",
+					Labels:    labels,
+					OwnerReferences: []v1.OwnerReference{{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       deploy.Name,
+						UID:        deploy.UID,
+						Controller: utils.Pointer(true),
+					}},
+				},
+				Spec: appsv1.ReplicaSetSpec{
+					Replicas: utils.Pointer[int32](1),
+					Selector: &v1.LabelSelector{MatchLabels: labels},
+					Template: template,
+				},
+			}
+			Expect(k8sClient.Create(ctx, rs)).To(Succeed())
+
+			By("Creating unscheduled Pod")
+			// webhook resolves workload from informer cache, Pods created before RS is synced get no cost
+			Eventually(func(g Gomega) {
+				pod := &corev1.Pod{
+					ObjectMeta: v1.ObjectMeta{
+						GenerateName: "webhook-pod-",
+						Namespace:    "default",
+						Labels:       labels,
+						OwnerReferences: []v1.OwnerReference{{
+							APIVersion: "apps/v1",
+							Kind:       "ReplicaSet",
+							Name:       rs.Name,
+							UID:        rs.UID,
+							Controller: utils.Pointer(true),
+						}},
+					},
+					Spec: template.Spec,
+				}
+				g.Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+				cost, ok := controller.GetPodDeletionCost(pod)
+				g.Expect(ok).To(BeTrue())
+				g.Expect(cost).To(Equal(controller.ProvisionalCost))
+				g.Expect(controller.IsProvisional(pod)).To(BeTrue())
+			}, "10s", "300ms").Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lablabs/pod-deletion-cost-controller/api/v1alpha1"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationPrefix prefix of pod-deletion-cost configuration annotations. Policy parameters are named without it
+	AnnotationPrefix = "pod-deletion-cost.lablabs.io/"
+)
+
+// PolicyInstalled return true if PodDeletionCostPolicy CRD is installed in cluster. CRDs of Helm chart are not
+// upgraded by helm upgrade, so controller upgraded without applying them runs without policies
+func PolicyInstalled(mapper meta.RESTMapper) (bool, error) {
+	gvk := v1alpha1.GroupVersion.WithKind("PodDeletionCostPolicy")
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// SelectPolicy return policy governing workload, nil if no policy matches. When more policies match,
+// the oldest one wins (name is used as tie-breaker). Policies with invalid selector are ignored
+func SelectPolicy(policies []v1alpha1.PodDeletionCostPolicy, obj metav1.Object) *v1alpha1.PodDeletionCostPolicy {
+	matched := make([]*v1alpha1.PodDeletionCostPolicy, 0)
+	for i := range policies {
+		p := &policies[i]
+		if p.DeletionTimestamp != nil || p.Namespace != obj.GetNamespace() {
+			continue
+		}
+		selector, err := PolicySelector(p)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			matched = append(matched, p)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		ti, tj := matched[i].CreationTimestamp, matched[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	return matched[0]
+}
+
+// PolicySelector return label selector of policy. Empty selector matches everything
+func PolicySelector(policy *v1alpha1.PodDeletionCostPolicy) (labels.Selector, error) {
+	if policy.Spec.Selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(policy.Spec.Selector)
+}
+
+// HasOwnConfiguration return true if workload is configured by its annotations, policies are not applied then
+func HasOwnConfiguration(obj metav1.Object) bool {
+	_, ok := obj.GetAnnotations()[EnableAnnotation]
+	return ok
+}
+
+// ApplyPolicy return copy of Workload configured by policy. Policy algorithm and parameters are rendered
+// as pod-deletion-cost.lablabs.io/* annotations, annotations already present on workload take precedence
+func ApplyPolicy(w *module.Workload, policy *v1alpha1.PodDeletionCostPolicy) *module.Workload {
+	return applySpec(w, policy.Spec, policy.Name)
+}
+
+func applySpec(w *module.Workload, spec v1alpha1.PodDeletionCostPolicySpec, policyName string) *module.Workload {
+	annotations := map[string]string{
+		EnableAnnotation: "true",
+		TypeAnnotation:   spec.Algorithm,
+	}
+	for k, v := range spec.Parameters {
+		annotations[AnnotationPrefix+k] = v
+	}
+	for k, v := range w.GetAnnotations() {
+		annotations[k] = v
+	}
+	obj := w.DeepCopyObject().(client.Object)
+	obj.SetAnnotations(annotations)
+	return &module.Workload{
+		Object:   obj,
+		Kind:     w.Kind,
+		Template: w.Template,
+		Policy:   policyName,
+	}
+}
+
+// ResolvePolicy return Workload configured by policy matching it, or by cluster default policy when no policy
+// in namespace matches or policies are disabled. Workload with EnableAnnotation is returned as is
+func (r *WorkloadResolver) ResolvePolicy(ctx context.Context, w *module.Workload) (*module.Workload, error) {
+	if HasOwnConfiguration(w) {
+		return w, nil
+	}
+	if !r.withoutPolicies {
+		policies := &v1alpha1.PodDeletionCostPolicyList{}
+		if err := r.client.List(ctx, policies, client.InNamespace(w.GetNamespace())); err != nil {
+			return nil, fmt.Errorf("unable to list policies: %w", err)
+		}
+		if policy := SelectPolicy(policies.Items, w); policy != nil {
+			return ApplyPolicy(w, policy), nil
+		}
+	}
+	if r.defaultPolicy == nil {
+		return w, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: w.GetNamespace()}, ns); err != nil {
+		return nil, fmt.Errorf("unable to get namespace %s: %w", w.GetNamespace(), err)
+	}
+	if !r.defaultPolicy.Matches(ns, w) {
+		return w, nil
+	}
+	return applySpec(w, r.defaultPolicy.PodDeletionCostPolicySpec, ""), nil
+}
+
+// recordAssignment record result of pod-deletion-cost assignment into status of policy governing Workload
+func recordAssignment(ctx context.Context, c client.Client, w *module.Workload, assignErr error) error {
+	policy := &v1alpha1.PodDeletionCostPolicy{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: w.GetNamespace(), Name: w.Policy}, policy); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFromWithOptions(policy.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionAssigned,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Assigned",
+		Message:            fmt.Sprintf("pod-deletion-cost assigned to pod of %s %s", w.Kind, w.GetName()),
+		ObservedGeneration: policy.Generation,
+	}
+	if assignErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "AssignmentFailed"
+		condition.Message = fmt.Sprintf("%s %s: %s", w.Kind, w.GetName(), assignErr.Error())
+	} else {
+		now := metav1.Now()
+		policy.Status.LastAssignmentTime = &now
+	}
+	meta.SetStatusCondition(&policy.Status.Conditions, condition)
+	return c.Status().Patch(ctx, policy, patch)
+}
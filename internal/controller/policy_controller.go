@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lablabs/pod-deletion-cost-controller/api/v1alpha1"
+	v1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// PolicyReconciler reconciles status of PodDeletionCostPolicy object
+type PolicyReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	Manager *Manager
+	// OwnerKinds additional kinds owning ReplicaSets, e.g. Argo Rollout, resolved via unstructured client
+	OwnerKinds []schema.GroupVersionKind
+}
+
+// Reconcile is called for each PodDeletionCostPolicy event
+func (r *PolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	policy := &v1alpha1.PodDeletionCostPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if policy.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+	original := policy.DeepCopy()
+
+	ready := metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Ready",
+		Message:            "policy is valid",
+		ObservedGeneration: policy.Generation,
+	}
+	matched, err := r.matchedWorkloads(ctx, policy)
+	switch {
+	case err != nil:
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "InvalidSelector"
+		ready.Message = err.Error()
+	case !r.Manager.HasType(policy.Spec.Algorithm):
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "UnknownAlgorithm"
+		ready.Message = fmt.Sprintf("algorithm %q is not registered", policy.Spec.Algorithm)
+	}
+	policy.Status.MatchedWorkloads = matched
+	policy.Status.ObservedGeneration = policy.Generation
+	meta.SetStatusCondition(&policy.Status.Conditions, ready)
+
+	if equality.Semantic.DeepEqual(original.Status, policy.Status) {
+		return ctrl.Result{}, nil
+	}
+	patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+	return ctrl.Result{}, r.Status().Patch(ctx, policy, patch)
+}
+
+// matchedWorkloads return workloads governed by policy. Workloads configured by annotations
+// or governed by older policy are not included
+func (r *PolicyReconciler) matchedWorkloads(ctx context.Context, policy *v1alpha1.PodDeletionCostPolicy) ([]v1alpha1.WorkloadReference, error) {
+	if _, err := PolicySelector(policy); err != nil {
+		return nil, err
+	}
+	policies := &v1alpha1.PodDeletionCostPolicyList{}
+	if err := r.List(ctx, policies, client.InNamespace(policy.Namespace)); err != nil {
+		return nil, err
+	}
+	workloads, err := NewWorkloadResolver(r.Client, r.OwnerKinds...).ListWorkloads(ctx, policy.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]v1alpha1.WorkloadReference, 0)
+	for _, w := range workloads {
+		if HasOwnConfiguration(w) {
+			continue
+		}
+		selected := SelectPolicy(policies.Items, w)
+		if selected == nil || selected.Name != policy.Name {
+			continue
+		}
+		matched = append(matched, v1alpha1.WorkloadReference{Kind: w.Kind, Name: w.GetName()})
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Kind != matched[j].Kind {
+			return matched[i].Kind < matched[j].Kind
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	return matched, nil
+}
+
+// SetupWithManager configure PolicyReconciler
+func (r *PolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.PodDeletionCostPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1alpha1.PodDeletionCostPolicy{}, handler.EnqueueRequestsFromMapFunc(mapWorkloadToPolicyReconcileFunc(r.Client)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1.Deployment{}, handler.EnqueueRequestsFromMapFunc(mapWorkloadToPolicyReconcileFunc(r.Client)),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&v1.ReplicaSet{}, handler.EnqueueRequestsFromMapFunc(mapWorkloadToPolicyReconcileFunc(r.Client)),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})))
+	for _, kind := range r.OwnerKinds {
+		owner := &unstructured.Unstructured{}
+		owner.SetGroupVersionKind(kind)
+		b = b.Watches(owner, handler.EnqueueRequestsFromMapFunc(mapWorkloadToPolicyReconcileFunc(r.Client)),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})))
+	}
+	return b.Named("poddeletioncostpolicy").Complete(r)
+}
+
+// mapWorkloadToPolicyReconcileFunc enqueue all policies in namespace of object, so their matched workloads are updated
+func mapWorkloadToPolicyReconcileFunc(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, object client.Object) []reconcile.Request {
+		log := logr.FromContext(ctx)
+		policies := &v1alpha1.PodDeletionCostPolicyList{}
+		if err := c.List(ctx, policies, client.InNamespace(object.GetNamespace())); err != nil {
+			log.Error(err, "unable to list policies")
+			return nil
+		}
+		reqs := make([]reconcile.Request, 0, len(policies.Items))
+		for _, p := range policies.Items {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: p.Namespace, Name: p.Name},
+			})
+		}
+		return reqs
+	}
+}
@@ -0,0 +1,241 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/api/v1alpha1"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	return s
+}
+
+func newPolicy(name string, age time.Duration, selector map[string]string) v1alpha1.PodDeletionCostPolicy {
+	return v1alpha1.PodDeletionCostPolicy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: v1.NewTime(time.Now().Add(-age)),
+		},
+		Spec: v1alpha1.PodDeletionCostPolicySpec{
+			Selector:   &v1.LabelSelector{MatchLabels: selector},
+			Algorithm:  "zone",
+			Parameters: map[string]string{"spread-by": "rack"},
+		},
+	}
+}
+
+func TestSelectPolicy(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "app",
+			Namespace: "default",
+			Labels:    map[string]string{"tier": "prod"},
+		},
+	}
+	invalid := newPolicy("invalid", 3*time.Hour, nil)
+	invalid.Spec.Selector = &v1.LabelSelector{
+		MatchExpressions: []v1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}},
+	}
+
+	tests := []struct {
+		name     string
+		policies []v1alpha1.PodDeletionCostPolicy
+		want     string
+	}{
+		{
+			name:     "no policy",
+			policies: nil,
+			want:     "",
+		},
+		{
+			name:     "selector does not match",
+			policies: []v1alpha1.PodDeletionCostPolicy{newPolicy("dev", time.Hour, map[string]string{"tier": "dev"})},
+			want:     "",
+		},
+		{
+			name: "oldest matching policy wins",
+			policies: []v1alpha1.PodDeletionCostPolicy{
+				newPolicy("newer", time.Minute, map[string]string{"tier": "prod"}),
+				newPolicy("older", time.Hour, nil),
+			},
+			want: "older",
+		},
+		{
+			name:     "invalid selector is ignored",
+			policies: []v1alpha1.PodDeletionCostPolicy{invalid, newPolicy("valid", time.Minute, nil)},
+			want:     "valid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := controller.SelectPolicy(tt.policies, dep)
+			if tt.want == "" {
+				require.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			require.Equal(t, tt.want, got.Name)
+		})
+	}
+}
+
+func TestApplyPolicy(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			Annotations: map[string]string{controller.AnnotationPrefix + "spread-by": "zone"},
+		},
+	}
+	policy := newPolicy("policy", time.Hour, nil)
+
+	w := controller.ApplyPolicy(module.FromDeployment(dep), &policy)
+
+	require.True(t, controller.IsEnabled(w))
+	require.Equal(t, "zone", controller.GetType(w))
+	require.Equal(t, "zone", w.GetAnnotations()[controller.AnnotationPrefix+"spread-by"], "annotation has precedence")
+	require.Equal(t, "policy", w.Policy)
+	require.Len(t, dep.Annotations, 1, "original workload must not be modified")
+}
+
+func TestResolvePolicy(t *testing.T) {
+	policy := newPolicy("policy", time.Hour, nil)
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(&policy).Build()
+	r := controller.NewWorkloadResolver(c)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantEnabled bool
+		wantPolicy  string
+	}{
+		{
+			name:        "workload without annotations is configured by policy",
+			annotations: nil,
+			wantEnabled: true,
+			wantPolicy:  "policy",
+		},
+		{
+			name:        "opt-out annotation has precedence",
+			annotations: map[string]string{controller.EnableAnnotation: "false"},
+			wantEnabled: false,
+		},
+		{
+			name:        "enabled annotation has precedence",
+			annotations: map[string]string{controller.EnableAnnotation: "true"},
+			wantEnabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", Annotations: tt.annotations},
+			}
+			w, err := r.ResolvePolicy(context.Background(), module.FromDeployment(dep))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantEnabled, controller.IsEnabled(w))
+			require.Equal(t, tt.wantPolicy, w.Policy)
+		})
+	}
+}
+
+func TestResolvePolicyWithoutCRD(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+	dep := &appsv1.Deployment{ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default"}}
+
+	_, err := controller.NewWorkloadResolver(c).ResolvePolicy(context.Background(), module.FromDeployment(dep))
+	require.Error(t, err)
+
+	w, err := controller.NewWorkloadResolver(c).WithoutPolicies().ResolvePolicy(context.Background(), module.FromDeployment(dep))
+	require.NoError(t, err)
+	require.False(t, controller.IsEnabled(w))
+	require.Empty(t, w.Policy)
+}
+
+func TestPolicyInstalled(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	installed, err := controller.PolicyInstalled(mapper)
+	require.NoError(t, err)
+	require.False(t, installed)
+
+	mapper.Add(v1alpha1.GroupVersion.WithKind("PodDeletionCostPolicy"), meta.RESTScopeNamespace)
+	installed, err = controller.PolicyInstalled(mapper)
+	require.NoError(t, err)
+	require.True(t, installed)
+}
+
+func TestPolicyReconcile(t *testing.T) {
+	policy := newPolicy("policy", time.Hour, map[string]string{"tier": "prod"})
+	older := newPolicy("older", 2*time.Hour, map[string]string{"app": "taken"})
+	newDeployment := func(name string, labels, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default", Labels: labels, Annotations: annotations},
+		}
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme(t)).
+		WithObjects(
+			&policy,
+			&older,
+			newDeployment("matched", map[string]string{"tier": "prod"}, nil),
+			newDeployment("other", map[string]string{"tier": "dev"}, nil),
+			newDeployment("annotated", map[string]string{"tier": "prod"}, map[string]string{controller.EnableAnnotation: "true"}),
+			newDeployment("taken", map[string]string{"tier": "prod", "app": "taken"}, nil),
+		).
+		WithStatusSubresource(&v1alpha1.PodDeletionCostPolicy{}).
+		Build()
+	mng := controller.NewModuleManager()
+	require.NoError(t, mng.AddModule(&fakeModule{types: []string{"zone"}}))
+	r := &controller.PolicyReconciler{Client: c, Manager: mng}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+	require.NoError(t, err)
+
+	out := &v1alpha1.PodDeletionCostPolicy{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(&policy), out))
+	require.Equal(t, []v1alpha1.WorkloadReference{{Kind: module.KindDeployment, Name: "matched"}}, out.Status.MatchedWorkloads)
+	require.True(t, meta.IsStatusConditionTrue(out.Status.Conditions, v1alpha1.ConditionReady))
+
+	out.Spec.Algorithm = "unknown"
+	require.NoError(t, c.Update(context.Background(), out))
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+	require.NoError(t, err)
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(&policy), out))
+	require.True(t, meta.IsStatusConditionFalse(out.Status.Conditions, v1alpha1.ConditionReady))
+}
+
+type fakeModule struct {
+	types []string
+}
+
+func (m *fakeModule) AcceptType() []string {
+	return m.types
+}
+
+func (m *fakeModule) Handle(_ context.Context, _ logr.Logger, _ *corev1.Pod, _ *module.Workload) error {
+	return nil
+}
@@ -3,7 +3,11 @@ package controller
 import (
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
@@ -18,6 +22,54 @@ func DeploymentPredicate() predicate.Predicate {
 	})
 }
 
+// ReplicaSetPredicate creates ReplicaSet predicate for filtering. Only ReplicaSets without owner are accepted,
+// owned ReplicaSets are configured by their Deployment
+func ReplicaSetPredicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		rs, ok := obj.(*v1.ReplicaSet)
+		if !ok {
+			return false
+		}
+		if metav1.GetControllerOf(rs) != nil {
+			return false
+		}
+		return IsEnabled(rs)
+	})
+}
+
+// OwnerPredicate creates predicate for filtering unstructured owners of kind, e.g. Argo Rollout.
+// Updates are accepted only when annotations changed
+func OwnerPredicate(kind schema.GroupVersionKind) predicate.Predicate {
+	return predicate.And(
+		predicate.AnnotationChangedPredicate{},
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			owner, ok := obj.(*unstructured.Unstructured)
+			if !ok || owner.GroupVersionKind() != kind {
+				return false
+			}
+			return IsEnabled(owner)
+		}),
+	)
+}
+
+// DisabledPredicate accepts updates of workloads with changed EnableAnnotation which are not enabled anymore,
+// so Pods managed by controller can be cleaned up
+func DisabledPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			oldValue := e.ObjectOld.GetAnnotations()[EnableAnnotation]
+			newValue := e.ObjectNew.GetAnnotations()[EnableAnnotation]
+			return oldValue != newValue && !IsEnabled(e.ObjectNew)
+		},
+	}
+}
+
 // PodPredicate creates Pod predicate for filtering
 func PodPredicate() predicate.Predicate {
 	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
@@ -5,10 +5,12 @@ import (
 	"time"
 
 	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/test/utils"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -145,3 +147,114 @@ func TestAcceptPod(t *testing.T) {
 		})
 	}
 }
+
+func TestAcceptReplicaSet(t *testing.T) {
+	pred := controller.ReplicaSetPredicate()
+	enabled := map[string]string{controller.EnableAnnotation: "true"}
+
+	tests := []struct {
+		name string
+		rs   *appsv1.ReplicaSet
+		want bool
+	}{
+		{
+			name: "bare replicaset without annotation → false",
+			rs:   &appsv1.ReplicaSet{},
+			want: false,
+		},
+		{
+			name: "bare replicaset enabled → true",
+			rs: &appsv1.ReplicaSet{
+				ObjectMeta: controllerruntime.ObjectMeta{Annotations: enabled},
+			},
+			want: true,
+		},
+		{
+			name: "owned replicaset enabled → false",
+			rs: &appsv1.ReplicaSet{
+				ObjectMeta: controllerruntime.ObjectMeta{
+					Annotations: enabled,
+					OwnerReferences: []v1.OwnerReference{
+						{Kind: "Deployment", Name: "app", Controller: utils.Pointer(true)},
+					},
+				},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pred.Create(event.CreateEvent{Object: tt.rs})
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAcceptOwner(t *testing.T) {
+	pred := controller.OwnerPredicate(controller.RolloutGVK)
+	newRollout := func(annotations map[string]string) *unstructured.Unstructured {
+		rollout := &unstructured.Unstructured{}
+		rollout.SetGroupVersionKind(controller.RolloutGVK)
+		rollout.SetAnnotations(annotations)
+		return rollout
+	}
+	enabled := map[string]string{controller.EnableAnnotation: "true"}
+
+	require.True(t, pred.Create(event.CreateEvent{Object: newRollout(enabled)}))
+	require.False(t, pred.Create(event.CreateEvent{Object: newRollout(nil)}))
+	require.False(t, pred.Update(event.UpdateEvent{ObjectOld: newRollout(enabled), ObjectNew: newRollout(enabled)}),
+		"update without annotation change → false")
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: newRollout(nil), ObjectNew: newRollout(enabled)}))
+}
+
+func TestDisabledPredicate(t *testing.T) {
+	pred := controller.DisabledPredicate()
+	withEnabled := func(value string) *appsv1.Deployment {
+		dep := &appsv1.Deployment{}
+		if value != "" {
+			dep.Annotations = map[string]string{controller.EnableAnnotation: value}
+		}
+		return dep
+	}
+
+	tests := []struct {
+		name string
+		old  *appsv1.Deployment
+		new  *appsv1.Deployment
+		want bool
+	}{
+		{
+			name: "annotation removed → true",
+			old:  withEnabled("true"),
+			new:  withEnabled(""),
+			want: true,
+		},
+		{
+			name: "opt-out added → true",
+			old:  withEnabled(""),
+			new:  withEnabled("false"),
+			want: true,
+		},
+		{
+			name: "enabled → false",
+			old:  withEnabled(""),
+			new:  withEnabled("true"),
+			want: false,
+		},
+		{
+			name: "annotation unchanged → false",
+			old:  withEnabled("true"),
+			new:  withEnabled("true"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pred.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new})
+			require.Equal(t, tt.want, got)
+		})
+	}
+	require.False(t, pred.Create(event.CreateEvent{Object: withEnabled("")}))
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// StatusPredicate accepts updates of Pods with changed health in status: container restart counts or status
+// of conditions. Unlike PodPredicate, Pods which are not Ready anymore are accepted when their cost is managed
+// by controller, so they can be re-ranked
+func StatusPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			if IsDeleting(newPod) || (!IsManaged(newPod) && !IsAccepted(newPod)) {
+				return false
+			}
+			return HealthChanged(oldPod, newPod)
+		},
+	}
+}
+
+// IgnoreStatusPredicate rejects updates changing only status of Pods which already have cost, they are reconciled
+// by StatusPredicate watch after debounce instead. Pods without cost or with provisional cost are not rejected,
+// so they get their cost without delay
+func IgnoreStatusPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return true
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return true
+			}
+			if !HasPodDeletionCost(oldPod) || IsProvisional(newPod) {
+				return true
+			}
+			return !statusOnly(oldPod, newPod)
+		},
+	}
+}
+
+// statusOnly return true if Pods differ only in status
+func statusOnly(oldPod, newPod *corev1.Pod) bool {
+	return equality.Semantic.DeepEqual(oldPod.Labels, newPod.Labels) &&
+		equality.Semantic.DeepEqual(oldPod.Annotations, newPod.Annotations) &&
+		equality.Semantic.DeepEqual(oldPod.DeletionTimestamp, newPod.DeletionTimestamp) &&
+		equality.Semantic.DeepEqual(oldPod.Spec, newPod.Spec)
+}
+
+// HealthChanged return true if restart count of any container or status of any condition differs between Pods
+func HealthChanged(oldPod, newPod *corev1.Pod) bool {
+	if RestartCount(oldPod) != RestartCount(newPod) {
+		return true
+	}
+	if len(oldPod.Status.Conditions) != len(newPod.Status.Conditions) {
+		return true
+	}
+	for _, c := range newPod.Status.Conditions {
+		if conditionStatus(oldPod, c.Type) != c.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// RestartCount return sum of restart counts of all containers of Pod
+func RestartCount(pod *corev1.Pod) int {
+	count := 0
+	for _, s := range pod.Status.ContainerStatuses {
+		count += int(s.RestartCount)
+	}
+	return count
+}
+
+func conditionStatus(pod *corev1.Pod, conditionType corev1.PodConditionType) corev1.ConditionStatus {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
+// enqueueAfter enqueue updated Pod after delay. Updates of Pod already waiting in queue are merged into
+// its pending request, so Pod is reconciled at most once per delay however often its status changes
+func enqueueAfter(delay time.Duration) handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: e.ObjectNew.GetNamespace(),
+				Name:      e.ObjectNew.GetName(),
+			}}, delay)
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestStatusPredicate(t *testing.T) {
+	pred := controller.StatusPredicate()
+	ready := &corev1.Pod{Status: corev1.PodStatus{
+		Phase:             corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{Name: "app"}},
+		Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+	}}
+	restarted := ready.DeepCopy()
+	restarted.Status.ContainerStatuses[0].RestartCount = 1
+	notReady := ready.DeepCopy()
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	managed := ready.DeepCopy()
+	managed.Annotations = map[string]string{
+		controller.PodDeletionCostAnnotation: "10",
+		controller.ManagedByAnnotation:       controller.ManagedByValue,
+	}
+	managedNotReady := managed.DeepCopy()
+	managedNotReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	relabeled := ready.DeepCopy()
+	relabeled.Labels = map[string]string{"k": "v"}
+	deleting := restarted.DeepCopy()
+	deleting.DeletionTimestamp = &v1.Time{}
+
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: restarted}))
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: managed, ObjectNew: managedNotReady}))
+	require.False(t, pred.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: notReady}), "unmanaged pod not ready")
+	require.False(t, pred.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: relabeled}))
+	require.False(t, pred.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: deleting}))
+	require.False(t, pred.Create(event.CreateEvent{Object: ready}))
+}
+
+func TestIgnoreStatusPredicate(t *testing.T) {
+	pred := controller.IgnoreStatusPredicate()
+	ready := &corev1.Pod{Status: corev1.PodStatus{
+		Phase:      corev1.PodRunning,
+		Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+	}}
+	notReady := ready.DeepCopy()
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	costed := ready.DeepCopy()
+	controller.ApplyPodDeletionCost(costed, 10)
+	costedNotReady := costed.DeepCopy()
+	costedNotReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	provisional := notReady.DeepCopy()
+	controller.ApplyProvisionalCost(provisional, 10)
+	relabeled := costed.DeepCopy()
+	relabeled.Labels = map[string]string{"k": "v"}
+
+	require.False(t, pred.Update(event.UpdateEvent{ObjectOld: costedNotReady, ObjectNew: costed}), "status of pod with cost")
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: notReady, ObjectNew: ready}), "pod without cost")
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: provisional, ObjectNew: provisional.DeepCopy()}))
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: costed}), "cost applied")
+	require.True(t, pred.Update(event.UpdateEvent{ObjectOld: costed, ObjectNew: relabeled}))
+	require.True(t, pred.Create(event.CreateEvent{Object: costed}))
+}
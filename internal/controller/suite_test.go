@@ -18,15 +18,24 @@ package controller_test
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/lablabs/pod-deletion-cost-controller/api/v1alpha1"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	webhookv1 "github.com/lablabs/pod-deletion-cost-controller/internal/webhook/v1"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
 	"github.com/lablabs/pod-deletion-cost-controller/test/utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -65,6 +75,7 @@ var _ = BeforeSuite(func() {
 	//
 	Expect(corev1.AddToScheme(scheme.Scheme)).To(Succeed())
 	Expect(appsv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(v1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
 
 	//
 	// 2️⃣ Start test environment
@@ -72,8 +83,12 @@ var _ = BeforeSuite(func() {
 	binaryPath, err := utils.GetK8sBinaryDir(5, []string{"bin", "k8s"})
 	Expect(err).NotTo(HaveOccurred())
 	testEnv = &envtest.Environment{
-		ErrorIfCRDPathMissing: false,
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "charts", "pod-deletion-cost-controller", "crds")},
+		ErrorIfCRDPathMissing: true,
 		BinaryAssetsDirectory: binaryPath,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			MutatingWebhooks: []*admissionregistrationv1.MutatingWebhookConfiguration{podWebhookConfiguration()},
+		},
 	}
 
 	cfg, err = testEnv.Start()
@@ -89,8 +104,14 @@ var _ = BeforeSuite(func() {
 	//
 	// 4️⃣ Create manager
 	//
+	webhookOptions := &testEnv.WebhookInstallOptions
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme.Scheme,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    webhookOptions.LocalServingHost,
+			Port:    webhookOptions.LocalServingPort,
+			CertDir: webhookOptions.LocalServingCertDir,
+		}),
 	})
 	Expect(err).NotTo(HaveOccurred())
 
@@ -103,6 +124,21 @@ var _ = BeforeSuite(func() {
 		Manager: moduleMng,
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
+	err = (&controller.PolicyReconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Manager: moduleMng,
+	}).SetupWithManager(mgr)
+	Expect(err).ToNot(HaveOccurred())
+
+	//
+	// 5️⃣ Register mutating webhook served to envtest API server
+	//
+	err = webhookv1.SetupPodWebhookWithManager(mgr, &webhookv1.PodCostDefaulter{
+		Manager:  moduleMng,
+		Resolver: controller.NewWorkloadResolver(mgr.GetClient()),
+	})
+	Expect(err).ToNot(HaveOccurred())
 
 	// 6️⃣ Start the manager in background
 	//
@@ -110,8 +146,50 @@ var _ = BeforeSuite(func() {
 		defer GinkgoRecover()
 		Expect(mgr.Start(ctx)).To(Succeed())
 	}()
+
+	// wait for webhook server to be ready
+	dialer := &net.Dialer{Timeout: time.Second}
+	addrPort := fmt.Sprintf("%s:%d", webhookOptions.LocalServingHost, webhookOptions.LocalServingPort)
+	Eventually(func() error {
+		conn, err := tls.DialWithDialer(dialer, "tcp", addrPort, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}).Should(Succeed())
 })
 
+// podWebhookConfiguration return configuration of Pod mutating webhook, envtest points its service to local
+// webhook server. Failures are not ignored, so broken webhook fails Pod creation in tests
+func podWebhookConfiguration() *admissionregistrationv1.MutatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-deletion-cost-controller"},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{{
+			Name: "mpod.pod-deletion-cost.lablabs.io",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Name:      "webhook-service",
+					Namespace: "default",
+					Path:      utils.Pointer("/mutate--v1-pod"),
+				},
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				},
+			}},
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+		}},
+	}
+}
+
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	cancel()
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeAnnotation name of algo type
+	TypeAnnotation = "health"
+	// WindowAnnotation sets how long readiness transition of Pod counts as flapping, e.g. 10m. Default is DefaultWindow
+	WindowAnnotation = "pod-deletion-cost.lablabs.io/health-window"
+	// DefaultWindow default window of readiness transitions
+	DefaultWindow = 10 * time.Minute
+)
+
+// Handler ranks Pods by their health, the least healthy Pods are removed first
+type Handler struct {
+	*zone.Handler
+}
+
+// NewHandler create new Handler
+func NewHandler(client client.Client) *Handler {
+	return &Handler{Handler: zone.NewBandHandler(client, []string{TypeAnnotation}, Band)}
+}
+
+// Validate validates health annotations of workload in addition to zone annotations
+func (h *Handler) Validate(ctx context.Context, w *module.Workload) ([]string, field.ErrorList) {
+	warnings, errs := h.Handler.Validate(ctx, w)
+	if value, ok := w.GetAnnotations()[WindowAnnotation]; ok {
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			errs = append(errs, field.Invalid(field.NewPath("metadata", "annotations").Key(WindowAnnotation), value,
+				"must be positive duration, e.g. 10m"))
+		}
+	}
+	return warnings, errs
+}
+
+// Band return band of Pod by its health score, Pods with higher score are in lower band and removed first
+func Band(_ *corev1.Node, pod *corev1.Pod, w *module.Workload) int {
+	return -Score(pod, Window(w), time.Now())
+}
+
+// Window return window of readiness transitions of workload, DefaultWindow if not set or invalid
+func Window(w *module.Workload) time.Duration {
+	if d, err := time.ParseDuration(w.GetAnnotations()[WindowAnnotation]); err == nil && d > 0 {
+		return d
+	}
+	return DefaultWindow
+}
+
+// RequeueAfter return delay until flapping point of Pod expires, so Pod is re-ranked as soon as its readiness settles
+// and not by unrelated reconcile later. Zero is returned when Pod is not flapping
+func (h *Handler) RequeueAfter(pod *corev1.Pod, w *module.Workload) time.Duration {
+	until, ok := flappingUntil(pod, Window(w))
+	if !ok {
+		return 0
+	}
+	return max(time.Until(until), 0)
+}
+
+// Score return how unhealthy Pod is at now, 0 is healthy. Score is sum of container restart counts, one point
+// while Ready condition transitioned within window (flapping), and one point for each of Ready, ContainersReady and
+// readiness gate conditions which is not true. Transitions within window after Pod started are its startup and
+// do not count as flapping
+func Score(pod *corev1.Pod, window time.Duration, now time.Time) int {
+	score := controller.RestartCount(pod)
+	if until, ok := flappingUntil(pod, window); ok && now.Before(until) {
+		score++
+	}
+	conditions := []corev1.PodConditionType{corev1.PodReady, corev1.ContainersReady}
+	for _, gate := range pod.Spec.ReadinessGates {
+		conditions = append(conditions, gate.ConditionType)
+	}
+	for _, conditionType := range conditions {
+		if c := condition(pod, conditionType); c == nil || c.Status != corev1.ConditionTrue {
+			score++
+		}
+	}
+	return score
+}
+
+// flappingUntil return time flapping point of Pod expires. False is returned when last transition of Ready
+// condition is part of Pod startup
+func flappingUntil(pod *corev1.Pod, window time.Duration) (time.Time, bool) {
+	c := condition(pod, corev1.PodReady)
+	if c == nil || pod.Status.StartTime == nil || c.LastTransitionTime.IsZero() {
+		return time.Time{}, false
+	}
+	if !c.LastTransitionTime.After(pod.Status.StartTime.Add(window)) {
+		return time.Time{}, false
+	}
+	return c.LastTransitionTime.Add(window), true
+}
+
+func condition(pod *corev1.Pod, conditionType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == conditionType {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
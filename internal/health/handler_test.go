@@ -0,0 +1,174 @@
+package health_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/health"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newPod(name, nodeName string, restarts int32, ready corev1.ConditionStatus, transition time.Time) *corev1.Pod {
+	pod := testutil.NewPod(name, "app-1", nodeName, 0)
+	started := v1.NewTime(transition.Add(-time.Hour))
+	pod.Status.StartTime = &started
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", RestartCount: restarts}}
+	pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodReady, Status: ready, LastTransitionTime: v1.NewTime(transition)},
+		{Type: corev1.ContainersReady, Status: ready, LastTransitionTime: v1.NewTime(transition)},
+	}
+	return pod
+}
+
+func TestScore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fresh := newPod("fresh", "", 0, corev1.ConditionTrue, now.Add(-time.Minute))
+	fresh.Status.StartTime = &v1.Time{Time: now.Add(-2 * time.Minute)}
+	gated := newPod("gated", "", 0, corev1.ConditionTrue, now.Add(-time.Hour))
+	gated.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: "example.com/ready"}}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want int
+	}{
+		{
+			name: "healthy",
+			pod:  newPod("healthy", "", 0, corev1.ConditionTrue, now.Add(-time.Hour)),
+			want: 0,
+		},
+		{
+			name: "restarts",
+			pod:  newPod("restarts", "", 3, corev1.ConditionTrue, now.Add(-time.Hour)),
+			want: 3,
+		},
+		{
+			name: "flapping readiness",
+			pod:  newPod("flapping", "", 1, corev1.ConditionTrue, now.Add(-time.Minute)),
+			want: 2,
+		},
+		{
+			name: "first readiness of fresh pod is not flapping",
+			pod:  fresh,
+			want: 0,
+		},
+		{
+			name: "flapping point expired",
+			pod:  newPod("expired", "", 1, corev1.ConditionTrue, now.Add(-health.DefaultWindow)),
+			want: 1,
+		},
+		{
+			name: "not ready",
+			pod:  newPod("not-ready", "", 0, corev1.ConditionFalse, now.Add(-time.Hour)),
+			want: 2,
+		},
+		{
+			name: "missing readiness gate condition",
+			pod:  gated,
+			want: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, health.Score(tt.pod, health.DefaultWindow, now))
+		})
+	}
+}
+
+func TestRequeueAfter(t *testing.T) {
+	w := module.FromDeployment(&appsv1.Deployment{})
+	h := health.NewHandler(testutil.NewFakeClient())
+
+	flapping := newPod("flapping", "", 1, corev1.ConditionTrue, time.Now().Add(-time.Minute))
+	after := h.RequeueAfter(flapping, w)
+	require.Greater(t, after, health.DefaultWindow-2*time.Minute)
+	require.LessOrEqual(t, after, health.DefaultWindow-time.Minute)
+
+	settled := newPod("settled", "", 1, corev1.ConditionTrue, time.Now().Add(-time.Hour))
+	require.Zero(t, h.RequeueAfter(settled, w))
+
+	fresh := newPod("fresh", "", 0, corev1.ConditionTrue, time.Now().Add(-time.Minute))
+	fresh.Status.StartTime = &v1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	require.Zero(t, h.RequeueAfter(fresh, w), "startup readiness is not flapping")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  string
+		wantErr bool
+	}{
+		{name: "valid window", window: "15m"},
+		{name: "invalid window", window: "soon", wantErr: true},
+		{name: "negative window", window: "-1m", wantErr: true},
+	}
+
+	h := health.NewHandler(testutil.NewFakeClient())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := module.FromDeployment(&appsv1.Deployment{ObjectMeta: v1.ObjectMeta{
+				Annotations: map[string]string{health.WindowAnnotation: tt.window},
+			}})
+			_, errs := h.Validate(context.Background(), w)
+			require.Equal(t, tt.wantErr, len(errs) > 0, "errors: %v", errs)
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "app",
+			Namespace: "default",
+			UID:       "app",
+		},
+	}
+	old := time.Now().Add(-time.Hour)
+	pods := []*corev1.Pod{
+		newPod("a-healthy", "node-a", 0, corev1.ConditionTrue, old),
+		newPod("a-restarting", "node-a", 3, corev1.ConditionTrue, old),
+		newPod("b-healthy", "node-b", 0, corev1.ConditionTrue, old),
+		newPod("b-flapping", "node-b", 1, corev1.ConditionFalse, time.Now().Add(-time.Minute)),
+	}
+	for _, p := range pods[:3] {
+		controller.ApplyPodDeletionCost(p, 1)
+	}
+	objs := []client.Object{
+		testutil.NewNode("node-a", map[string]string{zone.TopologyZoneAnnotation: "a"}),
+		testutil.NewNode("node-b", map[string]string{zone.TopologyZoneAnnotation: "b"}),
+	}
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	c := testutil.NewFakeClient(objs...)
+
+	h := health.NewHandler(c)
+	require.Equal(t, []string{health.TypeAnnotation}, h.AcceptType())
+	// flapping pod is not Ready anymore, but its managed cost keeps it ranked
+	flapping := pods[3]
+	flapping.Annotations = map[string]string{
+		controller.PodDeletionCostAnnotation: "2",
+		controller.ManagedByAnnotation:       controller.ManagedByValue,
+	}
+	require.NoError(t, c.Update(context.Background(), flapping))
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), flapping, module.FromDeployment(dep)))
+
+	// healthy pods are balanced by zone, the flapping pod scores 4 and is removed before the restarting one
+	testutil.RequireCosts(t, c, map[string]int{
+		"a-healthy":    math.MaxInt32,
+		"b-healthy":    math.MaxInt32 - 1,
+		"a-restarting": math.MaxInt32 - 2,
+		"b-flapping":   math.MaxInt32 - 3,
+	})
+}
@@ -0,0 +1,31 @@
+package health
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	//Name of module
+	Name = "health"
+)
+
+// Register register health module
+func Register(log logr.Logger, r zone.Registrator, client client.Client, algoTypes []string) error {
+	if slices.Contains(algoTypes, Name) || len(algoTypes) == 0 {
+		h := NewHandler(client)
+		err := r.AddModule(h)
+		if err != nil {
+			return fmt.Errorf("register health module failed: %w", err)
+		}
+		log.WithValues("module", Name).Info("registered")
+		return nil
+	}
+	log.V(2).WithValues("module", Name).Info("NOT registered")
+
+	return nil
+}
@@ -2,14 +2,33 @@ package module
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
-	appv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 // Handler represent main module interface
 type Handler interface {
 	AcceptType() []string
-	Handle(ctx context.Context, log logr.Logger, pod *corev1.Pod, dep *appv1.Deployment) error
+	Handle(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *Workload) error
+}
+
+// Initializer is optional interface of Handler computing initial pod-deletion-cost of Pod being created.
+// Pod is not persisted yet, so its name and UID can be empty
+type Initializer interface {
+	InitialCost(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *Workload) (int, error)
+}
+
+// Validator is optional interface of Handler validating its configuration annotations of workload.
+// Errors reject workload, warnings are returned to user
+type Validator interface {
+	Validate(ctx context.Context, w *Workload) ([]string, field.ErrorList)
+}
+
+// Requeuer is optional interface of Handler whose cost of Pod depends on time. Pod is reconciled again after returned
+// delay, zero means no requeue
+type Requeuer interface {
+	RequeueAfter(pod *corev1.Pod, w *Workload) time.Duration
 }
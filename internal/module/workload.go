@@ -0,0 +1,67 @@
+package module
+
+import (
+	"fmt"
+
+	appv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KindDeployment kind of Deployment workload
+	KindDeployment = "Deployment"
+	// KindReplicaSet kind of ReplicaSet workload
+	KindReplicaSet = "ReplicaSet"
+)
+
+// Workload represent object owning Pods which carries pod-deletion-cost configuration,
+// e.g. Deployment, Argo Rollout or ReplicaSet without owner
+type Workload struct {
+	client.Object
+	// Kind of workload object
+	Kind string
+	// Template Pod template of workload
+	Template corev1.PodTemplateSpec
+	// Policy name of PodDeletionCostPolicy configuring workload, empty when workload is configured by annotations
+	Policy string
+}
+
+// FromDeployment create Workload from Deployment
+func FromDeployment(dep *appv1.Deployment) *Workload {
+	return &Workload{
+		Object:   dep,
+		Kind:     KindDeployment,
+		Template: dep.Spec.Template,
+	}
+}
+
+// FromReplicaSet create Workload from ReplicaSet
+func FromReplicaSet(rs *appv1.ReplicaSet) *Workload {
+	return &Workload{
+		Object:   rs,
+		Kind:     KindReplicaSet,
+		Template: rs.Spec.Template,
+	}
+}
+
+// FromUnstructured create Workload from unstructured object, e.g. Argo Rollout. Pod template is read from spec.template
+func FromUnstructured(u *unstructured.Unstructured) (*Workload, error) {
+	w := &Workload{
+		Object: u,
+		Kind:   u.GetKind(),
+	}
+	template, found, err := unstructured.NestedMap(u.Object, "spec", "template")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pod template of %s %s/%s: %w", w.Kind, u.GetNamespace(), u.GetName(), err)
+	}
+	if !found {
+		return w, nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, &w.Template); err != nil {
+		return nil, fmt.Errorf("unable to convert pod template of %s %s/%s: %w", w.Kind, u.GetNamespace(), u.GetName(), err)
+	}
+	return w, nil
+}
@@ -0,0 +1,24 @@
+package node
+
+import (
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeAnnotation name of algo type
+	TypeAnnotation = "node"
+)
+
+// NewHandler create new Handler giving each Node its own ladder, so scale-down removes one Pod per Node
+// before removing a second one from any Node. Mode and scope annotations of zone module are supported
+func NewHandler(client client.Client) *zone.Handler {
+	return zone.NewDomainHandler(client, []string{TypeAnnotation}, Domain)
+}
+
+// Domain spreads Pods by spec.nodeName
+func Domain(_ *corev1.Node, pod *corev1.Pod, _ *module.Workload) string {
+	return pod.Spec.NodeName
+}
@@ -0,0 +1,55 @@
+package node_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/node"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandle(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	// nodes carry no zone label, single-zone cluster
+	first := testutil.NewPod("first", "app-1", "node-a", math.MaxInt32)
+	second := testutil.NewPod("second", "app-1", "node-a", 0)
+	other := testutil.NewPod("other", "app-1", "node-b", 0)
+	c := testutil.NewFakeClient(testutil.NewNode("node-a", nil), testutil.NewNode("node-b", nil), first, second, other)
+
+	h := node.NewHandler(c)
+	require.Equal(t, []string{node.TypeAnnotation}, h.AcceptType())
+	w := module.FromDeployment(dep)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), second, w))
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), other, w))
+
+	testutil.RequireCosts(t, c, map[string]int{
+		"first":  math.MaxInt32,
+		"second": math.MaxInt32 - 1,
+		"other":  math.MaxInt32,
+	})
+}
+
+func TestHandleDeletedNode(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	pod := testutil.NewPod("pod", "app-1", "node-a", 0)
+	orphan := testutil.NewPod("orphan", "app-1", "node-gone", math.MaxInt32)
+	c := testutil.NewFakeClient(testutil.NewNode("node-a", nil), pod, orphan)
+
+	h := node.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), pod, module.FromDeployment(dep)))
+
+	testutil.RequireCosts(t, c, map[string]int{
+		"pod":    math.MaxInt32,
+		"orphan": math.MaxInt32,
+	})
+}
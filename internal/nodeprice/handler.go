@@ -0,0 +1,61 @@
+package nodeprice
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TypeAnnotation name of algo type
+	TypeAnnotation = "node-price"
+	// LabelAnnotation overrides Node label key used to look up Node price
+	LabelAnnotation = "pod-deletion-cost.lablabs.io/price-label"
+	// InstanceTypeLabel default Node label key used to look up Node price
+	InstanceTypeLabel = "node.kubernetes.io/instance-type"
+	// priceUnit price resolution of bands, prices differing by less are in the same band
+	priceUnit = 1e-6
+)
+
+// NewHandler create new Handler ranking Pods on more expensive Nodes below Pods on cheaper Nodes, so they are
+// removed first and consolidation can remove expensive Nodes. Pods are spread by zone inside each price band,
+// zone annotations are supported
+func NewHandler(client client.Client, prices Prices) *zone.Handler {
+	return zone.NewBandHandler(client, []string{TypeAnnotation}, Band(prices))
+}
+
+// Band return BandFunc placing Pods into bands by negative price of their Node, so Pods on the most expensive Nodes
+// are in the lowest band
+func Band(prices Prices) zone.BandFunc {
+	return func(node *corev1.Node, _ *corev1.Pod, w *module.Workload) int {
+		return -int(math.Round(Price(prices, node, w) / priceUnit))
+	}
+}
+
+// Price return price of Node. Value of price label is looked up in prices, value which is a number itself
+// is used as price. Node with unknown price has price of OtherKey entry or 0, i.e. is removed last
+func Price(prices Prices, node *corev1.Node, w *module.Workload) float64 {
+	if node == nil {
+		return 0
+	}
+	value := node.Labels[Label(w)]
+	if price, ok := prices[value]; ok {
+		return price
+	}
+	if price, err := strconv.ParseFloat(value, 64); err == nil && price >= 0 {
+		return price
+	}
+	return prices[OtherKey]
+}
+
+// Label return Node label key used to look up Node price
+func Label(w *module.Workload) string {
+	if key, ok := w.GetAnnotations()[LabelAnnotation]; ok && key != "" {
+		return key
+	}
+	return InstanceTypeLabel
+}
@@ -0,0 +1,95 @@
+package nodeprice_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/nodeprice"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newNode(name, zoneName, instanceType string) *corev1.Node {
+	return testutil.NewNode(name, map[string]string{
+		zone.TopologyZoneAnnotation: zoneName,
+		nodeprice.InstanceTypeLabel: instanceType,
+	})
+}
+
+func TestPrice(t *testing.T) {
+	prices := nodeprice.Prices{"m5.large": 0.096, nodeprice.OtherKey: 0.5}
+	w := module.FromDeployment(&appsv1.Deployment{})
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        float64
+	}{
+		{
+			name:   "price table",
+			labels: map[string]string{nodeprice.InstanceTypeLabel: "m5.large"},
+			want:   0.096,
+		},
+		{
+			name:   "unknown instance type",
+			labels: map[string]string{nodeprice.InstanceTypeLabel: "m5.xlarge"},
+			want:   0.5,
+		},
+		{
+			name:        "price label",
+			labels:      map[string]string{"example.com/price": "1.25"},
+			annotations: map[string]string{nodeprice.LabelAnnotation: "example.com/price"},
+			want:        1.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w.SetAnnotations(tt.annotations)
+			node := &corev1.Node{ObjectMeta: v1.ObjectMeta{Labels: tt.labels}}
+			require.Equal(t, tt.want, nodeprice.Price(prices, node, w))
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	prices := nodeprice.Prices{"m5.large": 0.096, "m5.xlarge": 0.192}
+	pods := []*corev1.Pod{
+		testutil.NewPod("xlarge-a", "app-1", "xlarge-a", math.MaxInt32),
+		testutil.NewPod("large-a-1", "app-1", "large-a", math.MaxInt32-1),
+		testutil.NewPod("large-a-2", "app-1", "large-a", math.MaxInt32-2),
+		testutil.NewPod("large-b", "app-1", "large-b", math.MaxInt32),
+	}
+	objs := []client.Object{
+		newNode("xlarge-a", "a", "m5.xlarge"),
+		newNode("large-a", "a", "m5.large"),
+		newNode("large-b", "b", "m5.large"),
+	}
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	c := testutil.NewFakeClient(objs...)
+
+	h := nodeprice.NewHandler(c, prices)
+	require.Equal(t, []string{nodeprice.TypeAnnotation}, h.AcceptType())
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), pods[0], module.FromDeployment(dep)))
+
+	// pod on the most expensive node is removed first, zones are balanced among pods on cheaper nodes
+	testutil.RequireCosts(t, c, map[string]int{
+		"large-b":   math.MaxInt32,
+		"large-a-1": math.MaxInt32 - 1,
+		"large-a-2": math.MaxInt32 - 2,
+		"xlarge-a":  math.MaxInt32 - 3,
+	})
+}
@@ -0,0 +1,31 @@
+package nodeprice
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"k8s.io/utils/strings/slices"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	//Name of module
+	Name = "node-price"
+)
+
+// Register register node-price module using price table
+func Register(log logr.Logger, r zone.Registrator, client client.Client, algoTypes []string, prices Prices) error {
+	if slices.Contains(algoTypes, Name) || len(algoTypes) == 0 {
+		h := NewHandler(client, prices)
+		err := r.AddModule(h)
+		if err != nil {
+			return fmt.Errorf("register node-price module failed: %w", err)
+		}
+		log.WithValues("module", Name, "prices", len(prices)).Info("registered")
+		return nil
+	}
+	log.V(2).WithValues("module", Name).Info("NOT registered")
+
+	return nil
+}
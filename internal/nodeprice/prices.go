@@ -0,0 +1,39 @@
+package nodeprice
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OtherKey sets price of Nodes whose price label value is missing in table
+	OtherKey = "*"
+)
+
+// Prices price of Node by value of price label, e.g. instance type
+type Prices map[string]float64
+
+// LoadPrices load Prices from YAML file
+func LoadPrices(path string) (Prices, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read price table: %w", err)
+	}
+	return ParsePrices(data)
+}
+
+// ParsePrices parse Prices from YAML map of label value to price
+func ParsePrices(data []byte) (Prices, error) {
+	prices := Prices{}
+	if err := yaml.UnmarshalStrict(data, &prices); err != nil {
+		return nil, fmt.Errorf("unable to parse price table: %w", err)
+	}
+	for value, price := range prices {
+		if price < 0 {
+			return nil, fmt.Errorf("invalid price of %q: %v", value, price)
+		}
+	}
+	return prices, nil
+}
@@ -0,0 +1,54 @@
+package nodeprice_test
+
+import (
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/nodeprice"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePrices(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    nodeprice.Prices
+		wantErr bool
+	}{
+		{
+			name: "instance types and default",
+			data: `
+m5.large: 0.096
+m5.xlarge: 0.192
+"*": 0.1
+`,
+			want: nodeprice.Prices{"m5.large": 0.096, "m5.xlarge": 0.192, "*": 0.1},
+		},
+		{
+			name: "empty table",
+			data: ``,
+			want: nodeprice.Prices{},
+		},
+		{
+			name:    "price is not a number",
+			data:    `m5.large: cheap`,
+			wantErr: true,
+		},
+		{
+			name:    "negative price",
+			data:    `m5.large: -1`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nodeprice.ParsePrices([]byte(tt.data))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package testutil provides fixtures shared by tests of zone handler and algorithm modules
+package testutil
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	// Namespace of Pods created by NewPod
+	Namespace = "default"
+)
+
+// NewNode return Node with labels
+func NewNode(name string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+// NewReplicaSet return ReplicaSet controlled by Deployment in Namespace. UID of ReplicaSet equals its name
+func NewReplicaSet(name string, dep *appsv1.Deployment) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: Namespace,
+			UID:       types.UID(name),
+			OwnerReferences: []v1.OwnerReference{
+				{Kind: "Deployment", Name: dep.Name, UID: dep.UID, Controller: ptr.To(true)},
+			},
+		},
+	}
+}
+
+// NewPod return Running and Ready Pod of ReplicaSet rs bound to Node. Cost managed by controller is applied
+// unless it is 0. UID of Pod and of its ReplicaSet equals their names
+func NewPod(name, rs, nodeName string, cost int) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            name,
+			Namespace:       Namespace,
+			UID:             types.UID(name),
+			OwnerReferences: []v1.OwnerReference{{Kind: "ReplicaSet", Name: rs, UID: types.UID(rs)}},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	if cost != 0 {
+		controller.ApplyPodDeletionCost(pod, cost)
+	}
+	return pod
+}
+
+// NewForeignPod return Pod like NewPod, but its cost is set by user or other tool and is not managed by controller
+func NewForeignPod(name, rs, nodeName string, cost int) *corev1.Pod {
+	pod := NewPod(name, rs, nodeName, 0)
+	pod.Annotations = map[string]string{controller.PodDeletionCostAnnotation: strconv.Itoa(cost)}
+	return pod
+}
+
+// NewFakeClient return fake client with objects and indexes registered by controller
+func NewFakeClient(objs ...client.Object) client.Client {
+	return NewFakeClientBuilder(objs...).Build()
+}
+
+// NewFakeClientBuilder return builder of fake client with objects and indexes registered by controller,
+// e.g. to add interceptors
+func NewFakeClientBuilder(objs ...client.Object) *fake.ClientBuilder {
+	return fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(objs...).
+		WithIndex(&corev1.Pod{}, controller.PodToRSIndex, func(obj client.Object) []string {
+			return ownerUIDs(obj, "ReplicaSet")
+		}).
+		WithIndex(&corev1.Pod{}, controller.PodToNodeIndex, func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).
+		WithIndex(&appsv1.ReplicaSet{}, controller.RsToDeploymentIndex, func(obj client.Object) []string {
+			return ownerUIDs(obj, "Deployment")
+		})
+}
+
+// GetCost return cost of Pod in Namespace, test fails when Pod has no cost
+func GetCost(t *testing.T, c client.Client, name string) int {
+	t.Helper()
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: Namespace, Name: name}, pod))
+	cost, ok := controller.GetPodDeletionCost(pod)
+	require.True(t, ok, "pod %s has no cost", name)
+	return cost
+}
+
+// RequireCosts checks costs of Pods in Namespace by Pod name
+func RequireCosts(t *testing.T, c client.Client, want map[string]int) {
+	t.Helper()
+	for name, cost := range want {
+		require.Equal(t, cost, GetCost(t, c, name), "pod %s", name)
+	}
+}
+
+func ownerUIDs(obj client.Object, kind string) []string {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Kind == kind {
+			return []string{string(owner.UID)}
+		}
+	}
+	return nil
+}
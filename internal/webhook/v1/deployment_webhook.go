@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupDeploymentWebhookWithManager registers validating webhook of pod-deletion-cost annotations of Deployments
+func SetupDeploymentWebhookWithManager(mgr ctrl.Manager, v *DeploymentValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&appsv1.Deployment{}).
+		WithValidator(v).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-apps-v1-deployment,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=deployments,verbs=create;update,versions=v1,name=vdeployment.pod-deletion-cost.lablabs.io,admissionReviewVersions=v1
+
+// DeploymentValidator rejects Deployments with malformed pod-deletion-cost.lablabs.io annotations or algorithm type
+// not registered in controller.Manager. Updates not changing these annotations are always admitted
+type DeploymentValidator struct {
+	Manager *controller.Manager
+}
+
+var _ admission.CustomValidator = &DeploymentValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *DeploymentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	dep, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Deployment but got %T", obj)
+	}
+	return v.validate(ctx, dep)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *DeploymentValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldDep, ok := oldObj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Deployment but got %T", oldObj)
+	}
+	dep, ok := newObj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Deployment but got %T", newObj)
+	}
+	if equality.Semantic.DeepEqual(configAnnotations(oldDep), configAnnotations(dep)) {
+		return nil, nil
+	}
+	return v.validate(ctx, dep)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *DeploymentValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *DeploymentValidator) validate(ctx context.Context, dep *appsv1.Deployment) (admission.Warnings, error) {
+	errs := controller.ValidateAnnotations(dep)
+	var warnings []string
+	_, typed := dep.Annotations[controller.TypeAnnotation]
+	if typed || controller.IsEnabled(dep) {
+		var moduleErrs field.ErrorList
+		warnings, moduleErrs = v.Manager.Validate(ctx, module.FromDeployment(dep))
+		errs = append(errs, moduleErrs...)
+	}
+	if len(errs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind(), dep.Name, errs)
+}
+
+// configAnnotations return pod-deletion-cost.lablabs.io annotations of Deployment
+func configAnnotations(dep *appsv1.Deployment) map[string]string {
+	annotations := make(map[string]string)
+	for k, v := range dep.Annotations {
+		if strings.HasPrefix(k, controller.AnnotationPrefix) {
+			annotations[k] = v
+		}
+	}
+	return annotations
+}
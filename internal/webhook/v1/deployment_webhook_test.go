@@ -0,0 +1,140 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	webhookv1 "github.com/lablabs/pod-deletion-cost-controller/internal/webhook/v1"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeploymentValidator(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: "node-a", Labels: map[string]string{zone.TopologyZoneAnnotation: "a"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(node).Build()
+	m := controller.NewModuleManager()
+	require.NoError(t, zone.Register(logr.Discard(), m, c, nil))
+	v := &webhookv1.DeploymentValidator{Manager: m}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{
+			name:        "no annotations",
+			annotations: nil,
+		},
+		{
+			name: "valid configuration",
+			annotations: map[string]string{
+				controller.EnableAnnotation: "true",
+				controller.TypeAnnotation:   zone.TypeAnnotation,
+				zone.SpreadByAnnotation:     zone.TopologyZoneAnnotation,
+				zone.ModeAnnotation:         zone.ModeCompact,
+			},
+		},
+		{
+			name: "unknown type",
+			annotations: map[string]string{
+				controller.EnableAnnotation: "true",
+				controller.TypeAnnotation:   "zones",
+			},
+			wantErr: true,
+		},
+		{
+			name:        "malformed enabled",
+			annotations: map[string]string{controller.EnableAnnotation: "yes"},
+			wantErr:     true,
+		},
+		{
+			name: "unknown mode",
+			annotations: map[string]string{
+				controller.EnableAnnotation: "true",
+				zone.ModeAnnotation:         "compacted",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown foreign-cost",
+			annotations: map[string]string{
+				controller.EnableAnnotation:      "true",
+				controller.ForeignCostAnnotation: "ignore",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid zone weights",
+			annotations: map[string]string{
+				controller.EnableAnnotation: "true",
+				zone.WeightsAnnotation:      "a=2,b=0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown zone preference mode",
+			annotations: map[string]string{
+				controller.EnableAnnotation:   "true",
+				zone.PreferenceAnnotation:     "a,b",
+				zone.PreferenceModeAnnotation: "drain",
+			},
+			wantErr: true,
+		},
+		{
+			name: "spread-by carried by no node",
+			annotations: map[string]string{
+				controller.EnableAnnotation: "true",
+				zone.SpreadByAnnotation:     "example.com/rack",
+			},
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", Annotations: tt.annotations},
+			}
+			warnings, err := v.ValidateCreate(context.Background(), dep)
+			if tt.wantErr {
+				require.True(t, apierrors.IsInvalid(err), "expected invalid error, got %v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantWarning, len(warnings) > 0)
+		})
+	}
+}
+
+func TestDeploymentValidatorUpdate(t *testing.T) {
+	m := controller.NewModuleManager()
+	v := &webhookv1.DeploymentValidator{Manager: m}
+	invalid := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			Annotations: map[string]string{controller.EnableAnnotation: "yes"},
+		},
+	}
+	scaled := invalid.DeepCopy()
+	scaled.Spec.Replicas = new(int32)
+
+	_, err := v.ValidateUpdate(context.Background(), invalid, scaled)
+	require.NoError(t, err, "update not changing annotations must be admitted")
+
+	changed := invalid.DeepCopy()
+	changed.Annotations[controller.EnableAnnotation] = "ture"
+	_, err = v.ValidateUpdate(context.Background(), invalid, changed)
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logr "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupPodWebhookWithManager registers mutating webhook assigning initial pod-deletion-cost to created Pods
+func SetupPodWebhookWithManager(mgr ctrl.Manager, d *PodCostDefaulter) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.pod-deletion-cost.lablabs.io,admissionReviewVersions=v1
+
+// PodCostDefaulter assigns provisional pod-deletion-cost to Pods of enabled workloads at creation, so Pods are not
+// deleted blindly before controller assigns their cost. Errors never block Pod creation
+type PodCostDefaulter struct {
+	Manager  *controller.Manager
+	Resolver *controller.WorkloadResolver
+}
+
+var _ admission.CustomDefaulter = &PodCostDefaulter{}
+
+// Default implements admission.CustomDefaulter
+func (d *PodCostDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod but got %T", obj)
+	}
+	if pod.Namespace == "" {
+		if req, err := admission.RequestFromContext(ctx); err == nil {
+			pod.Namespace = req.Namespace
+		}
+	}
+	log := logr.FromContext(ctx).WithValues("pod", pod.GenerateName+pod.Name, "namespace", pod.Namespace)
+	if controller.HasPodDeletionCost(pod) {
+		return nil
+	}
+	w, err := d.Resolver.GetWorkload(ctx, pod)
+	if err != nil {
+		log.V(2).Info(err.Error())
+		return nil
+	}
+	cost, ok, err := d.Manager.InitialCost(ctx, log, pod, w)
+	if err != nil {
+		log.V(2).Info("unable to compute initial cost", "error", err.Error())
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	controller.ApplyProvisionalCost(pod, cost)
+	log.V(2).WithValues(controller.PodDeletionCostAnnotation, cost).Info("assigned provisional cost")
+	return nil
+}
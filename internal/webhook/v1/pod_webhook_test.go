@@ -0,0 +1,82 @@
+package v1_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	webhookv1 "github.com/lablabs/pod-deletion-cost-controller/internal/webhook/v1"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodCostDefaulter(t *testing.T) {
+	enabled := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "enabled",
+			Namespace:   "default",
+			UID:         "enabled",
+			Annotations: map[string]string{controller.EnableAnnotation: "true"},
+		},
+	}
+	disabled := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "disabled", Namespace: "default", UID: "disabled"},
+	}
+	enabledRS := testutil.NewReplicaSet("enabled-1", enabled)
+	disabledRS := testutil.NewReplicaSet("disabled-1", disabled)
+	newPod := func(name string, rs *appsv1.ReplicaSet, node string) *corev1.Pod {
+		return testutil.NewPod(name, rs.Name, node, 0)
+	}
+	running := newPod("running", enabledRS, "node-a")
+	controller.ApplyPodDeletionCost(running, math.MaxInt32)
+	node := &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: "node-a", Labels: map[string]string{zone.TopologyZoneAnnotation: "a"}},
+	}
+	c := testutil.NewFakeClient(enabled, disabled, enabledRS, disabledRS, running, node)
+	m := controller.NewModuleManager()
+	require.NoError(t, zone.Register(logr.Discard(), m, c, nil))
+	d := &webhookv1.PodCostDefaulter{Manager: m, Resolver: controller.NewWorkloadResolver(c)}
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		wantCost int
+		wantSet  bool
+	}{
+		{
+			name:     "bound pod gets next free slot of zone",
+			pod:      newPod("bound", enabledRS, "node-a"),
+			wantCost: math.MaxInt32 - 1,
+			wantSet:  true,
+		},
+		{
+			name:     "unscheduled pod gets provisional cost",
+			pod:      newPod("unscheduled", enabledRS, ""),
+			wantCost: controller.ProvisionalCost,
+			wantSet:  true,
+		},
+		{
+			name:    "pod of disabled workload is not mutated",
+			pod:     newPod("other", disabledRS, "node-a"),
+			wantSet: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, d.Default(context.Background(), tt.pod))
+			cost, ok := controller.GetPodDeletionCost(tt.pod)
+			require.Equal(t, tt.wantSet, ok)
+			if tt.wantSet {
+				require.Equal(t, tt.wantCost, cost)
+				require.True(t, controller.IsProvisional(tt.pod))
+			}
+		})
+	}
+}
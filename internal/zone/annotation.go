@@ -1,30 +1,209 @@
 package zone
 
 import (
-	appsv1 "k8s.io/api/apps/v1"
+	"fmt"
+	"strconv"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	// TopologyZoneAnnotation Default annotation used by Kubernetes
 	TopologyZoneAnnotation = "topology.kubernetes.io/zone"
-	// SpreadByAnnotation overrides name of annotation used for spreading pod-deletion-cost logic
+	// SpreadByAnnotation overrides name of annotation used for spreading pod-deletion-cost logic. Comma separated
+	// list of keys ordered from the top level, e.g. region,zone,hostname, enables hierarchical spreading
 	SpreadByAnnotation = "pod-deletion-cost.lablabs.io/spread-by"
+	// ModeAnnotation selects how zone ladder is maintained. Default mode assigns next free slot to new Pods only
+	ModeAnnotation = "pod-deletion-cost.lablabs.io/mode"
+	// ModeCompact recomputes whole zone ladder whenever zone membership changes, so ladder has no gaps
+	ModeCompact = "compact"
+	// ModeSkew ranks Pods of all zones together, so next victims always come from the most populated zone
+	ModeSkew = "skew"
+	// WeightsAnnotation sets target proportions of zones as comma separated list of zone=weight, e.g. a=2,b=1.
+	// Pods of all zones are ranked together, so scale-down keeps replicas proportional to weights
+	WeightsAnnotation = "pod-deletion-cost.lablabs.io/zone-weights"
+	// OtherZonesKey stands for zones not listed in WeightsAnnotation or PreferenceAnnotation
+	OtherZonesKey = "*"
+	// DefaultWeight is weight of zones missing in WeightsAnnotation, unless set by OtherZonesKey
+	DefaultWeight = 1
+	// PreferenceAnnotation lists zones in preference order as comma separated list, Pods of the first zone are kept
+	// the longest and Pods of the last zone are removed first. Entry * stands for unlisted zones, which are placed
+	// after listed zones when it is missing. Pods of all zones are ranked together
+	PreferenceAnnotation = "pod-deletion-cost.lablabs.io/zone-preference"
+	// PreferenceModeAnnotation selects how PreferenceAnnotation is applied. Default mode is PreferenceTieBreaker
+	PreferenceModeAnnotation = "pod-deletion-cost.lablabs.io/zone-preference-mode"
+	// PreferenceTieBreaker keeps zones balanced and uses preference only to order Pods of zones with equal share,
+	// e.g. which zone loses Pod first when all zones have the same number of Pods
+	PreferenceTieBreaker = "tie-breaker"
+	// PreferenceStrict removes all Pods of less preferred zone before Pods of more preferred zone
+	PreferenceStrict = "strict"
+	// BandSpreadAnnotation disables spreading by zone inside bands of banded algorithms and inside order of sorting
+	// algorithms when set to "false"
+	BandSpreadAnnotation = "pod-deletion-cost.lablabs.io/band-spread"
+	// ScopeAnnotation selects set of Pods ranked together. Default scope is ReplicaSet
+	ScopeAnnotation = "pod-deletion-cost.lablabs.io/scope"
+	// ScopeDeployment ranks Pods of all ReplicaSets owned by workload together, so zones stay balanced during rollouts
+	ScopeDeployment = "deployment"
 )
 
 // GetSpreadByAnnotation get SpreadByAnnotation annotation
-func GetSpreadByAnnotation(node *corev1.Node, deployment *appsv1.Deployment) string {
-	if deployment == nil {
+func GetSpreadByAnnotation(node *corev1.Node, workload metav1.Object) string {
+	if workload == nil {
 		return ""
 	}
 	if node == nil {
 		return ""
 	}
-	if deployment.Annotations == nil {
+	if workload.GetAnnotations() == nil {
 		return node.Labels[TopologyZoneAnnotation]
 	}
-	if spreadBy, ok := deployment.Annotations[SpreadByAnnotation]; ok {
+	if spreadBy, ok := workload.GetAnnotations()[SpreadByAnnotation]; ok {
 		return node.Labels[spreadBy]
 	}
 	return node.Labels[TopologyZoneAnnotation]
 }
+
+// GetSpreadByKeys get list of label keys of SpreadByAnnotation, nil if annotation is not set
+func GetSpreadByKeys(workload metav1.Object) []string {
+	if workload == nil || workload.GetAnnotations() == nil {
+		return nil
+	}
+	value, ok := workload.GetAnnotations()[SpreadByAnnotation]
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0)
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// GetMode get ModeAnnotation
+func GetMode(workload metav1.Object) string {
+	if workload == nil || workload.GetAnnotations() == nil {
+		return ""
+	}
+	return workload.GetAnnotations()[ModeAnnotation]
+}
+
+// GetScope get ScopeAnnotation
+func GetScope(workload metav1.Object) string {
+	if workload == nil || workload.GetAnnotations() == nil {
+		return ""
+	}
+	return workload.GetAnnotations()[ScopeAnnotation]
+}
+
+// Weights weight of zones by zone value
+type Weights map[string]int
+
+// Of return weight of zone, OtherZonesKey entry or DefaultWeight is used for zones missing in map
+func (w Weights) Of(zone string) int {
+	if weight, ok := w[zone]; ok {
+		return weight
+	}
+	if weight, ok := w[OtherZonesKey]; ok {
+		return weight
+	}
+	return DefaultWeight
+}
+
+// GetWeights parse WeightsAnnotation, nil if annotation is not set
+func GetWeights(workload metav1.Object) (Weights, error) {
+	if workload == nil || workload.GetAnnotations() == nil {
+		return nil, nil
+	}
+	value, ok := workload.GetAnnotations()[WeightsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	weights := make(Weights)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		zone, weight, found := strings.Cut(entry, "=")
+		zone = strings.TrimSpace(zone)
+		if !found || zone == "" {
+			return nil, fmt.Errorf("invalid weight %q, expected zone=weight", entry)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(weight))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid weight of zone %q, expected positive integer", zone)
+		}
+		weights[zone] = n
+	}
+	return weights, nil
+}
+
+// Preference order of zones by PreferenceAnnotation
+type Preference struct {
+	rank  map[string]int
+	other int
+	// Strict is true in PreferenceStrict mode
+	Strict bool
+}
+
+// Rank return position of zone in preference order, zone with lower rank is kept longer
+func (p Preference) Rank(zone string) int {
+	if rank, ok := p.rank[zone]; ok {
+		return rank
+	}
+	return p.other
+}
+
+// GetPreference parse PreferenceAnnotation and PreferenceModeAnnotation. Without annotations all zones have
+// the same rank
+func GetPreference(workload metav1.Object) (Preference, error) {
+	preference := Preference{rank: make(map[string]int)}
+	if workload == nil || workload.GetAnnotations() == nil {
+		return preference, nil
+	}
+	switch mode := workload.GetAnnotations()[PreferenceModeAnnotation]; mode {
+	case "", PreferenceTieBreaker:
+	case PreferenceStrict:
+		preference.Strict = true
+	default:
+		return preference, fmt.Errorf("unsupported preference mode %q", mode)
+	}
+	value, ok := workload.GetAnnotations()[PreferenceAnnotation]
+	if !ok {
+		return preference, nil
+	}
+	preference.other = -1
+	rank := 0
+	for _, zone := range strings.Split(value, ",") {
+		if zone = strings.TrimSpace(zone); zone == "" {
+			continue
+		}
+		if zone == OtherZonesKey {
+			preference.other = rank
+		} else {
+			preference.rank[zone] = rank
+		}
+		rank++
+	}
+	if preference.other == -1 {
+		preference.other = rank
+	}
+	return preference, nil
+}
+
+// IsBandSpread return true if Pods are spread by zone inside bands. Spreading is enabled unless
+// BandSpreadAnnotation is "false"
+func IsBandSpread(workload metav1.Object) bool {
+	if workload == nil || workload.GetAnnotations() == nil {
+		return true
+	}
+	value, ok := workload.GetAnnotations()[BandSpreadAnnotation]
+	if !ok {
+		return true
+	}
+	spread, err := strconv.ParseBool(value)
+	return err != nil || spread
+}
@@ -1,6 +1,8 @@
 package zone_test
 
 import (
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
@@ -77,3 +79,199 @@ func TestGetSpreadByAnnotation(t *testing.T) {
 		})
 	}
 }
+
+func TestGetMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		depAnn map[string]string
+		want   string
+	}{
+		{
+			name:   "no annotations → default mode",
+			depAnn: nil,
+			want:   "",
+		},
+		{
+			name:   "compact mode",
+			depAnn: map[string]string{zone.ModeAnnotation: zone.ModeCompact},
+			want:   zone.ModeCompact,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: controllerruntime.ObjectMeta{
+					Annotations: tt.depAnn,
+				},
+			}
+			if got := zone.GetMode(dep); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetScope(t *testing.T) {
+	dep := &appsv1.Deployment{}
+	if got := zone.GetScope(dep); got != "" {
+		t.Fatalf("expected empty scope, got %q", got)
+	}
+	dep.Annotations = map[string]string{zone.ScopeAnnotation: zone.ScopeDeployment}
+	if got := zone.GetScope(dep); got != zone.ScopeDeployment {
+		t.Fatalf("expected %q, got %q", zone.ScopeDeployment, got)
+	}
+}
+
+func TestGetSpreadByKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		depAnn map[string]string
+		want   []string
+	}{
+		{
+			name:   "no annotations → nil",
+			depAnn: nil,
+			want:   nil,
+		},
+		{
+			name:   "single key",
+			depAnn: map[string]string{zone.SpreadByAnnotation: "rack"},
+			want:   []string{"rack"},
+		},
+		{
+			name:   "ordered list with spaces",
+			depAnn: map[string]string{zone.SpreadByAnnotation: "region, zone ,hostname,"},
+			want:   []string{"region", "zone", "hostname"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: controllerruntime.ObjectMeta{
+					Annotations: tt.depAnn,
+				},
+			}
+			if got := zone.GetSpreadByKeys(dep); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		depAnn  map[string]string
+		want    zone.Weights
+		wantErr bool
+	}{
+		{
+			name:   "no annotations → nil",
+			depAnn: nil,
+			want:   nil,
+		},
+		{
+			name:   "weights with spaces and default",
+			depAnn: map[string]string{zone.WeightsAnnotation: "a=2, b = 1,*=3,"},
+			want:   zone.Weights{"a": 2, "b": 1, "*": 3},
+		},
+		{
+			name:    "missing weight",
+			depAnn:  map[string]string{zone.WeightsAnnotation: "a"},
+			wantErr: true,
+		},
+		{
+			name:    "zero weight",
+			depAnn:  map[string]string{zone.WeightsAnnotation: "a=0"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: controllerruntime.ObjectMeta{
+					Annotations: tt.depAnn,
+				},
+			}
+			got, err := zone.GetWeights(dep)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWeightsOf(t *testing.T) {
+	if got := (zone.Weights{"a": 2}).Of("b"); got != zone.DefaultWeight {
+		t.Fatalf("expected %d, got %d", zone.DefaultWeight, got)
+	}
+	if got := (zone.Weights{"a": 2, zone.OtherZonesKey: 3}).Of("b"); got != 3 {
+		t.Fatalf("expected 3, got %d", got)
+	}
+	if got := (zone.Weights{"a": 2}).Of("a"); got != 2 {
+		t.Fatalf("expected 2, got %d", got)
+	}
+}
+
+func TestGetPreference(t *testing.T) {
+	tests := []struct {
+		name       string
+		depAnn     map[string]string
+		wantRanks  map[string]int
+		wantStrict bool
+		wantErr    bool
+	}{
+		{
+			name:      "no annotations → same rank",
+			depAnn:    nil,
+			wantRanks: map[string]int{"a": 0, "b": 0},
+		},
+		{
+			name:      "unlisted zones after listed ones",
+			depAnn:    map[string]string{zone.PreferenceAnnotation: "home, b"},
+			wantRanks: map[string]int{"home": 0, "b": 1, "c": 2},
+		},
+		{
+			name: "unlisted zones in place of wildcard, strict",
+			depAnn: map[string]string{
+				zone.PreferenceAnnotation:     "home,*,expensive",
+				zone.PreferenceModeAnnotation: zone.PreferenceStrict,
+			},
+			wantRanks:  map[string]int{"home": 0, "c": 1, "expensive": 2},
+			wantStrict: true,
+		},
+		{
+			name:    "unknown mode",
+			depAnn:  map[string]string{zone.PreferenceModeAnnotation: "drain"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: controllerruntime.ObjectMeta{
+					Annotations: tt.depAnn,
+				},
+			}
+			got, err := zone.GetPreference(dep)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got.Strict != tt.wantStrict {
+				t.Fatalf("expected strict %v, got %v", tt.wantStrict, got.Strict)
+			}
+			for z, rank := range tt.wantRanks {
+				if r := got.Rank(z); r != rank {
+					t.Fatalf("expected rank %d of zone %q, got %d", rank, z, r)
+				}
+			}
+		})
+	}
+}
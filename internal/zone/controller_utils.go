@@ -3,6 +3,9 @@ package zone
 import (
 	"fmt"
 	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 // DeletionCostPool pool
@@ -25,6 +28,12 @@ func (p DeletionCostPool) AddValue(cost int) {
 	p[cost] = struct{}{}
 }
 
+// Has return true if cost is in pool
+func (p DeletionCostPool) Has(cost int) bool {
+	_, has := p[cost]
+	return has
+}
+
 // FindNextFree find new available slot
 func (p DeletionCostPool) FindNextFree() (int, error) {
 	if len(p) == 0 {
@@ -39,3 +48,134 @@ func (p DeletionCostPool) FindNextFree() (int, error) {
 	}
 	return 0, fmt.Errorf("no deletion cost slot found")
 }
+
+// LadderCost return cost of rank position in ladder, rank 0 is the most protected Pod
+func LadderCost(rank int) int {
+	return math.MaxInt32 - rank
+}
+
+// SortByDeletionCost sorts Pods from the most protected to the least protected one.
+// Pods without cost are placed after Pods with cost, older Pods first.
+func SortByDeletionCost(pods []corev1.Pod, costOf func(pod *corev1.Pod) (int, bool)) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		ci, oki := costOf(&pods[i])
+		cj, okj := costOf(&pods[j])
+		if oki != okj {
+			return oki
+		}
+		if oki && ci != cj {
+			return ci > cj
+		}
+		ti, tj := pods[i].CreationTimestamp, pods[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
+
+// Balance configures distribution of Pods among domains by Interleave
+type Balance struct {
+	// Weight of domain, all domains have weight 1 when nil
+	Weight func(domain string) int
+	// Rank of domain in preference order, domain with lower rank is kept longer. All domains have the same rank when nil
+	Rank func(domain string) int
+	// Strict orders domains by Rank first, so all Pods of domain are removed before Pods of domain with lower rank.
+	// Otherwise Rank only breaks ties of domains with equal share
+	Strict bool
+}
+
+// Interleave orders Pods from the most protected to the least protected one, so removing Pods from the end
+// keeps balance at each level of domain path. Path of Pod holds its domains ordered from the top level,
+// e.g. region, zone and node. Pods of the same leaf domain keep their relative order. Pods remaining after
+// removing Pods from the end are distributed among domains proportionally to Weight of balance, next Pod is taken
+// from the domain with the lowest (taken+1)/weight, i.e. by D'Hondt method. Ties are broken by Rank, then smaller
+// domains go first, so the largest domain loses its Pod first
+func Interleave(pods []corev1.Pod, pathOf func(pod *corev1.Pod) []string, balance Balance) []corev1.Pod {
+	if balance.Weight == nil {
+		balance.Weight = func(string) int { return 1 }
+	}
+	if balance.Rank == nil {
+		balance.Rank = func(string) int { return 0 }
+	}
+	return interleave(pods, pathOf, balance, 0)
+}
+
+func interleave(pods []corev1.Pod, pathOf func(pod *corev1.Pod) []string, balance Balance, level int) []corev1.Pod {
+	if len(pods) == 0 || level >= len(pathOf(&pods[0])) {
+		return pods
+	}
+	keys := make([]string, 0)
+	groups := make(map[string][]corev1.Pod)
+	for _, p := range pods {
+		key := pathOf(&p)[level]
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], p)
+	}
+	ordered := make([][]corev1.Pod, 0, len(keys))
+	weights := make([]int, 0, len(keys))
+	ranks := make([]int, 0, len(keys))
+	for _, key := range keys {
+		ordered = append(ordered, interleave(groups[key], pathOf, balance, level+1))
+		weights = append(weights, balance.Weight(key))
+		ranks = append(ranks, balance.Rank(key))
+	}
+	// preferred and smaller domains first on ties, so the largest domain loses its Pod first
+	index := make([]int, len(ordered))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		if ranks[index[i]] != ranks[index[j]] {
+			return ranks[index[i]] < ranks[index[j]]
+		}
+		return len(ordered[index[i]]) < len(ordered[index[j]])
+	})
+	taken := make([]int, len(ordered))
+	result := make([]corev1.Pod, 0, len(pods))
+	for len(result) < len(pods) {
+		next := -1
+		for _, i := range index {
+			if taken[i] >= len(ordered[i]) {
+				continue
+			}
+			if next == -1 {
+				next = i
+				continue
+			}
+			if balance.Strict && ranks[i] != ranks[next] {
+				// index is sorted by rank, domain with lower rank is already selected
+				continue
+			}
+			// (taken[i]+1)/weights[i] < (taken[next]+1)/weights[next]
+			if (taken[i]+1)*weights[next] < (taken[next]+1)*weights[i] {
+				next = i
+			}
+		}
+		result = append(result, ordered[next][taken[next]])
+		taken[next]++
+	}
+	return result
+}
+
+// OrderByBand orders Pods from the highest band to the lowest one, so all Pods of lower band are removed first.
+// Pods inside band are ordered by order
+func OrderByBand(pods []corev1.Pod, bandOf func(pod *corev1.Pod) int, order func(pods []corev1.Pod) []corev1.Pod) []corev1.Pod {
+	bands := make([]int, 0)
+	groups := make(map[int][]corev1.Pod)
+	for _, p := range pods {
+		band := bandOf(&p)
+		if _, ok := groups[band]; !ok {
+			bands = append(bands, band)
+		}
+		groups[band] = append(groups[band], p)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(bands)))
+	result := make([]corev1.Pod, 0, len(pods))
+	for _, band := range bands {
+		result = append(result, order(groups[band])...)
+	}
+	return result
+}
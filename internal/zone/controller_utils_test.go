@@ -3,8 +3,13 @@ package zone_test
 import (
 	"math"
 	"testing"
+	"time"
 
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestDeletionCostPool_FindNextFree(t *testing.T) {
@@ -64,3 +69,159 @@ func TestDeletionCostPool_FindNextFree(t *testing.T) {
 		})
 	}
 }
+
+func TestSortByDeletionCost(t *testing.T) {
+	older := v1.NewTime(time.Now().Add(-time.Hour))
+	newer := v1.NewTime(time.Now())
+	newPod := func(name string, created v1.Time, cost string) corev1.Pod {
+		pod := corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name, CreationTimestamp: created}}
+		if cost != "" {
+			pod.Annotations = map[string]string{controller.PodDeletionCostAnnotation: cost}
+		}
+		return pod
+	}
+
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		want []string
+	}{
+		{
+			name: "higher cost first",
+			pods: []corev1.Pod{newPod("a", older, "10"), newPod("b", older, "20")},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "pods without cost last, older first",
+			pods: []corev1.Pod{newPod("new", newer, ""), newPod("old", older, ""), newPod("cost", newer, "1")},
+			want: []string{"cost", "old", "new"},
+		},
+		{
+			name: "same cost -> older first",
+			pods: []corev1.Pod{newPod("b", newer, "5"), newPod("a", older, "5")},
+			want: []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone.SortByDeletionCost(tt.pods, controller.GetPodDeletionCost)
+			got := make([]string, 0, len(tt.pods))
+			for _, p := range tt.pods {
+				got = append(got, p.Name)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLadderCost(t *testing.T) {
+	require.Equal(t, math.MaxInt32, zone.LadderCost(0))
+	require.Equal(t, math.MaxInt32-2, zone.LadderCost(2))
+}
+
+func TestInterleave(t *testing.T) {
+	// pod name encodes its path: region, zone and index
+	newPod := func(name string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}}
+	}
+	pathOf := func(pod *corev1.Pod) []string {
+		return []string{pod.Name[:2], pod.Name[:4]}
+	}
+
+	tests := []struct {
+		name string
+		pods []string
+		want []string
+	}{
+		{
+			name: "single region interleaves zones",
+			pods: []string{"r1za1", "r1za2", "r1zb1"},
+			want: []string{"r1zb1", "r1za1", "r1za2"},
+		},
+		{
+			name: "regions are balanced before zones",
+			pods: []string{"r1za1", "r1za2", "r1zb1", "r1zb2", "r2za1", "r2zb1"},
+			want: []string{"r2za1", "r1za1", "r2zb1", "r1zb1", "r1za2", "r1zb2"},
+		},
+		{
+			name: "empty",
+			pods: nil,
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := make([]corev1.Pod, 0, len(tt.pods))
+			for _, name := range tt.pods {
+				pods = append(pods, newPod(name))
+			}
+			got := make([]string, 0, len(pods))
+			for _, p := range zone.Interleave(pods, pathOf, zone.Balance{}) {
+				got = append(got, p.Name)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInterleaveWeights(t *testing.T) {
+	pods := make([]corev1.Pod, 0)
+	for _, name := range []string{"a1", "a2", "a3", "a4", "a5", "a6", "b1", "b2", "b3", "c1", "c2", "c3"} {
+		pods = append(pods, corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}})
+	}
+	pathOf := func(pod *corev1.Pod) []string {
+		return []string{pod.Name[:1]}
+	}
+	weights := zone.Weights{"a": 2}
+
+	got := make([]string, 0, len(pods))
+	for _, p := range zone.Interleave(pods, pathOf, zone.Balance{Weight: weights.Of}) {
+		got = append(got, p.Name)
+	}
+	// D'Hondt allocation: each slot goes to the zone with the highest weight/(kept+1) quotient, ties to the zone
+	// ranked first, so zone a with double weight keeps exactly twice as many Pods as zones b and c at every
+	// fourth slot and converges to 2:1:1 in between
+	require.Equal(t, []string{"a1", "b1", "c1", "a2", "a3", "b2", "c2", "a4", "a5", "b3", "c3", "a6"}, got)
+}
+
+func TestInterleavePreference(t *testing.T) {
+	pods := make([]corev1.Pod, 0)
+	for _, name := range []string{"a1", "a2", "a3", "b1", "b2", "c1", "c2"} {
+		pods = append(pods, corev1.Pod{ObjectMeta: v1.ObjectMeta{Name: name}})
+	}
+	pathOf := func(pod *corev1.Pod) []string {
+		return []string{pod.Name[:1]}
+	}
+	// zone c is preferred, zone b is removed first
+	rank := func(domain string) int {
+		return map[string]int{"c": 0, "a": 1, "b": 2}[domain]
+	}
+
+	tests := []struct {
+		name   string
+		strict bool
+		want   []string
+	}{
+		{
+			name: "tie-breaker keeps balance",
+			want: []string{"c1", "a1", "b1", "c2", "a2", "b2", "a3"},
+		},
+		{
+			name:   "strict drains zones in order",
+			strict: true,
+			want:   []string{"c1", "c2", "a1", "a2", "a3", "b1", "b2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0, len(pods))
+			for _, p := range zone.Interleave(pods, pathOf, zone.Balance{Rank: rank, Strict: tt.strict}) {
+				got = append(got, p.Name)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -7,8 +7,9 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
 	"github.com/lablabs/pod-deletion-cost-controller/internal/expectations"
-	v1 "k8s.io/api/apps/v1"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -20,102 +21,522 @@ const (
 	DefaultHandlerTypeAnnotation = ""
 )
 
+// DomainFunc return spreading domain of Pod running on Node. Each domain has its own ladder
+type DomainFunc func(node *corev1.Node, pod *corev1.Pod, w *module.Workload) string
+
+// PathFunc return domains of Pod running on Node ordered from the top level, e.g. region, zone and node
+type PathFunc func(node *corev1.Node, pod *corev1.Pod) []string
+
+// BandFunc return band of Pod running on Node. All Pods of lower band are removed before Pods of higher band
+type BandFunc func(node *corev1.Node, pod *corev1.Pod, w *module.Workload) int
+
+// NodeRankFunc return rank of Nodes hosting Pods of scope by Node name. All Pods of Node with higher rank are removed
+// before Pods of Node with lower rank
+type NodeRankFunc func(ctx context.Context, nodes map[string]*corev1.Node, w *module.Workload) (map[string]int, error)
+
+// SortFunc orders Pods from the most protected to the least protected one
+type SortFunc func(pods []corev1.Pod, w *module.Workload)
+
+// ZoneDomain spreads Pods by Node label selected by SpreadKeys
+func ZoneDomain(node *corev1.Node, _ *corev1.Pod, w *module.Workload) string {
+	if node == nil {
+		return ""
+	}
+	return node.Labels[SpreadKeys(w)[0]]
+}
+
 // NewHandler create new Handler
 func NewHandler(client client.Client) *Handler {
+	h := NewDomainHandler(client, []string{TypeAnnotation, DefaultHandlerTypeAnnotation}, ZoneDomain)
+	h.labelSpread = true
+	return h
+}
+
+// NewDomainHandler create new Handler accepting algorithm types and spreading Pods by domain
+func NewDomainHandler(client client.Client, acceptType []string, domain DomainFunc) *Handler {
 	return &Handler{
-		client: client,
-		cache:  expectations.NewCache[types.UID, int](),
+		client:     client,
+		cache:      expectations.NewCache[types.UID, int](),
+		acceptType: acceptType,
+		domain:     domain,
 	}
 }
 
+// NewBandHandler create new Handler accepting algorithm types and ranking Pods of scope by band. Pods are spread
+// inside each band by Node labels of SpreadKeys, unless disabled by BandSpreadAnnotation
+func NewBandHandler(client client.Client, acceptType []string, band BandFunc) *Handler {
+	h := NewDomainHandler(client, acceptType, ZoneDomain)
+	h.labelSpread = true
+	h.band = band
+	return h
+}
+
+// NewNodeRankHandler create new Handler accepting algorithm types and ranking Pods of scope by rank of their Node,
+// so Pods of the same Node are removed together
+func NewNodeRankHandler(client client.Client, acceptType []string, rank NodeRankFunc) *Handler {
+	h := NewDomainHandler(client, acceptType, func(_ *corev1.Node, pod *corev1.Pod, _ *module.Workload) string {
+		return pod.Spec.NodeName
+	})
+	h.nodeRank = rank
+	return h
+}
+
+// NewSortHandler create new Handler accepting algorithm types and ranking Pods of scope in order of sort. Pods are
+// spread by Node labels of SpreadKeys keeping order of sort inside each zone, unless disabled by BandSpreadAnnotation
+func NewSortHandler(client client.Client, acceptType []string, sort SortFunc) *Handler {
+	h := NewDomainHandler(client, acceptType, ZoneDomain)
+	h.labelSpread = true
+	h.sort = sort
+	return h
+}
+
 // Handler handles reconcile loop for Pod/Deployment
 type Handler struct {
-	client client.Client
-	cache  *expectations.Cache[types.UID, int]
+	client     client.Client
+	cache      *expectations.Cache[types.UID, int]
+	acceptType []string
+	domain     DomainFunc
+	// labelSpread spreads by Node labels of SpreadKeys, enables global ranking by multiple keys
+	// and scope derived from topologySpreadConstraints
+	labelSpread bool
+	// band ranks Pods globally by band first, nil if handler has no bands
+	band BandFunc
+	// nodeRank ranks Pods globally by rank of their Node, nil if Nodes are not ranked
+	nodeRank NodeRankFunc
+	// sort ranks Pods globally in its order instead of order of current costs, nil if Pods keep order of costs
+	sort SortFunc
 }
 
 // AcceptType return accepted type of reconcile algorithm
 func (h *Handler) AcceptType() []string {
-	return []string{TypeAnnotation, DefaultHandlerTypeAnnotation}
+	return h.acceptType
 }
 
 // Handle handles main Reconcile for zone
-func (h *Handler) Handle(ctx context.Context, log logr.Logger, pod *corev1.Pod, dep *v1.Deployment) error {
+func (h *Handler) Handle(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *module.Workload) error {
+	if path := h.globalPath(w); path != nil {
+		return h.global(ctx, log, pod, w, path)
+	}
+	if GetMode(w) == ModeCompact {
+		return h.compact(ctx, log, pod, w)
+	}
+
+	node, err := h.getNode(ctx, nil, pod.Spec.NodeName)
+	if err != nil {
+		return err
+	}
+	domain := h.domainOf(node, pod, w)
+	if !controller.IsDeleting(pod) && controller.IsDraining(node, pod) {
+		return h.drain(ctx, log, pod, w, domain)
+	}
 
 	if controller.HasPodDeletionCost(pod) {
-		h.cache.Delete(pod.UID)
-		log.V(3).Info("clean cache, pod was sync")
-		return nil
+		if !controller.NeedsCost(pod, w) && !controller.IsDrainingCost(pod) && !movedDomain(pod, domain) {
+			h.cache.Delete(pod.UID)
+			log.V(3).Info("clean cache, pod was sync")
+			return nil
+		}
+		if _, cached := h.cache.Get(pod.UID); cached {
+			log.V(3).Info("cost not synced yet")
+			return nil
+		}
 	}
 
 	if controller.IsDeleting(pod) {
 		return nil
 	}
 
+	cost, err := h.nextFreeCost(ctx, log, pod, w)
+	if err != nil {
+		return err
+	}
+	h.cache.Set(pod.UID, cost)
+
+	err = h.patchCost(ctx, pod, cost, domain)
+	if err != nil {
+		// nothing is in flight, retry must not wait for cost to be synced
+		h.cache.Delete(pod.UID)
+		return err
+	}
+	log.WithValues(controller.PodDeletionCostAnnotation, cost).Info("updated")
+	return nil
+}
+
+// drain moves Pod on draining Node below ladder by controller.DrainingCost, its slot is freed for other Pods.
+// Foreign cost is replaced only when overridden by workload
+func (h *Handler) drain(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *module.Workload, domain string) error {
+	if controller.IsDrainingCost(pod) {
+		return nil
+	}
+	if h.isForeign(pod) && controller.GetForeignCost(w) != controller.ForeignCostOverride {
+		return nil
+	}
+	h.cache.Delete(pod.UID)
+	if err := h.patchCost(ctx, pod, controller.DrainingCost, domain); err != nil {
+		return err
+	}
+	log.WithValues(controller.PodDeletionCostAnnotation, controller.DrainingCost).Info("updated, node is draining")
+	return nil
+}
+
+// InitialCost computes provisional cost of Pod being created. Pod bound to Node gets next free slot of its zone,
+// unscheduled Pod or Pod ranked globally gets controller.ProvisionalCost. Cost is refined by Handle once Pod is Ready
+func (h *Handler) InitialCost(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *module.Workload) (int, error) {
+	if pod.Spec.NodeName == "" || h.globalPath(w) != nil {
+		return controller.ProvisionalCost, nil
+	}
+	return h.nextFreeCost(ctx, log, pod, w)
+}
+
+// nextFreeCost return next free slot of Pod zone. Provisional costs and foreign costs respected or overridden
+// by workload are not counted as occupied
+func (h *Handler) nextFreeCost(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *module.Workload) (int, error) {
 	pods := make([]corev1.Pod, 0)
-	err := h.listPodsInZone(ctx, log, dep, pod, &pods)
+	err := h.listPodsInZone(ctx, log, w, pod, &pods)
 	if err != nil {
-		return fmt.Errorf("unable to list pods: %w", err)
+		return 0, fmt.Errorf("unable to list pods: %w", err)
 	}
 
+	policy := controller.GetForeignCost(w)
 	pool := NewDeletionCostPool()
-	for _, pod := range pods {
-		if cost, exist := controller.GetPodDeletionCost(&pod); exist {
-			pool.AddValue(cost)
-			continue
+	for _, p := range pods {
+		if cost, exist := controller.GetPodDeletionCost(&p); exist && !controller.IsProvisional(&p) {
+			if !h.isForeign(&p) || (policy != controller.ForeignCostRespect && policy != controller.ForeignCostOverride) {
+				pool.AddValue(cost)
+				continue
+			}
 		}
-		if v, cached := h.cache.Get(pod.UID); cached {
+		if v, cached := h.cache.Get(p.UID); cached {
 			pool.AddValue(v)
 		}
 	}
 	cost, err := pool.FindNextFree()
 	if err != nil {
-		return fmt.Errorf("unable to find next cost value: %w", err)
+		return 0, fmt.Errorf("unable to find next cost value: %w", err)
 	}
-	h.cache.Set(pod.UID, cost)
+	return cost, nil
+}
+
+// compact recomputes ladder of the whole Pod zone, so zone always holds values MaxInt32, MaxInt32-1, ...
+// Relative order of Pods is kept, Pods leaving the zone free their slot for the rest. When zone of Pod changed
+// since its cost was assigned, e.g. Node labels changed, ladder of the previous zone is recomputed as well
+func (h *Handler) compact(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *module.Workload) error {
+	domain, err := h.getPodAnnotation(ctx, pod, w)
+	if err != nil {
+		return fmt.Errorf("unable to get pod annotation: %w", err)
+	}
+	domains := []string{domain}
+	if previous, ok := controller.GetDomain(pod); ok && previous != domain {
+		domains = append(domains, previous)
+	}
+	for _, d := range domains {
+		pods := make([]corev1.Pod, 0)
+		if err := h.listPodsInDomain(ctx, log, w, pod, d, &pods); err != nil {
+			return fmt.Errorf("unable to list pods: %w", err)
+		}
+		err := h.assignLadder(ctx, log, w, pods, func(members []corev1.Pod) []corev1.Pod {
+			SortByDeletionCost(members, h.costOf)
+			return members
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	patch := client.MergeFrom(pod.DeepCopy())
-	controller.ApplyPodDeletionCost(pod, cost)
-	err = h.client.Patch(ctx, pod, patch)
+// global ranks all Pods in scope together. Ladder interleaves domains of path, e.g. region, zone and node, so
+// scale-down always takes Pod from the most populated domain and keeps balance at each level of path
+func (h *Handler) global(ctx context.Context, log logr.Logger, pod *corev1.Pod, w *module.Workload, path PathFunc) error {
+	podList := &corev1.PodList{}
+	if err := h.listScopePods(ctx, w, pod, podList); err != nil {
+		return fmt.Errorf("unable to list pods: %w", err)
+	}
+	known := make(map[string]*corev1.Node)
+	nodes := make(map[string]*corev1.Node)
+	pods := make([]corev1.Pod, 0, len(podList.Items))
+	for _, p := range podList.Items {
+		// Pods not bound yet or left on deleted Node are not ranked
+		node, err := h.getNode(ctx, known, p.Spec.NodeName)
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			continue
+		}
+		nodes[node.Name] = node
+		pods = append(pods, p)
+	}
+	pathOf := func(p *corev1.Pod) []string {
+		return path(nodes[p.Spec.NodeName], p)
+	}
+	weights, err := GetWeights(w)
+	if err != nil {
+		return fmt.Errorf("%s: %w", WeightsAnnotation, err)
+	}
+	preference, err := GetPreference(w)
+	if err != nil {
+		return fmt.Errorf("%s: %w", PreferenceModeAnnotation, err)
+	}
+	balance := Balance{Weight: weights.Of, Rank: preference.Rank, Strict: preference.Strict}
+	if h.nodeRank != nil {
+		ranks, err := h.nodeRank(ctx, nodes, w)
+		if err != nil {
+			return fmt.Errorf("unable to rank nodes: %w", err)
+		}
+		balance.Rank = func(domain string) int {
+			return ranks[domain]
+		}
+		balance.Strict = true
+	}
+	log.V(3).Info("global ranking", "pod-count", len(pods), "weights", weights)
+	return h.assignLadder(ctx, log, w, pods, func(members []corev1.Pod) []corev1.Pod {
+		if h.sort != nil {
+			h.sort(members, w)
+		} else {
+			SortByDeletionCost(members, h.costOf)
+		}
+		if h.band == nil {
+			return Interleave(members, pathOf, balance)
+		}
+		bandOf := func(p *corev1.Pod) int {
+			return h.band(nodes[p.Spec.NodeName], p, w)
+		}
+		return OrderByBand(members, bandOf, func(band []corev1.Pod) []corev1.Pod {
+			return Interleave(band, pathOf, balance)
+		})
+	})
+}
+
+// assignLadder assigns ladder costs to Pods in order returned by order, from the most protected one. Foreign costs
+// are handled according to ForeignCostAnnotation, only overridden ones are ranked, included ones are skipped in ladder
+func (h *Handler) assignLadder(
+	ctx context.Context,
+	log logr.Logger,
+	w *module.Workload,
+	pods []corev1.Pod,
+	order func(members []corev1.Pod) []corev1.Pod,
+) error {
+	policy := controller.GetForeignCost(w)
+	reserved := NewDeletionCostPool()
+	members := make([]corev1.Pod, 0, len(pods))
+	for _, p := range pods {
+		if controller.IsDeleting(&p) {
+			h.cache.Delete(p.UID)
+			continue
+		}
+		if controller.IsProvisional(&p) && !controller.IsAccepted(&p) {
+			continue
+		}
+		if h.isForeign(&p) && policy != controller.ForeignCostOverride {
+			if cost, ok := controller.GetPodDeletionCost(&p); ok && policy != controller.ForeignCostRespect {
+				reserved.AddValue(cost)
+			}
+			continue
+		}
+		if _, ok := h.costOf(&p); !ok && !controller.IsAccepted(&p) {
+			continue
+		}
+		members = append(members, p)
+	}
+	nodes := make(map[string]*corev1.Node)
+	members, err := h.drainingLast(ctx, nodes, order(members))
 	if err != nil {
 		return err
 	}
-	log.WithValues(controller.PodDeletionCostAnnotation, cost).Info("updated")
+
+	rank := 0
+	for i := range members {
+		p := &members[i]
+		for reserved.Has(LadderCost(rank)) {
+			rank++
+		}
+		cost := LadderCost(rank)
+		rank++
+		node, err := h.getNode(ctx, nodes, p.Spec.NodeName)
+		if err != nil {
+			return err
+		}
+		domain := h.domainOf(node, p, w)
+		// overridden foreign cost is taken over even if it matches ladder
+		if current, ok := h.costOf(p); ok && current == cost && !controller.IsProvisional(p) && !h.isForeign(p) &&
+			!movedDomain(p, domain) {
+			continue
+		}
+		h.cache.Set(p.UID, cost)
+		if err := h.patchCost(ctx, p, cost, domain); err != nil {
+			// cached cost would match ladder on retry and real annotation would never be corrected
+			h.cache.Delete(p.UID)
+			return err
+		}
+		log.WithValues("pod", p.Name, controller.PodDeletionCostAnnotation, cost).Info("updated")
+	}
+	return nil
+}
+
+// drainingLast moves Pods on draining Nodes to the end of members, so they are at the bottom of ladder.
+// Relative order of Pods is kept, Nodes are memoized in nodes
+func (h *Handler) drainingLast(ctx context.Context, nodes map[string]*corev1.Node, members []corev1.Pod) ([]corev1.Pod, error) {
+	result := make([]corev1.Pod, 0, len(members))
+	draining := make([]corev1.Pod, 0)
+	for _, p := range members {
+		node, err := h.getNode(ctx, nodes, p.Spec.NodeName)
+		if err != nil {
+			return nil, err
+		}
+		if controller.IsDraining(node, &p) {
+			draining = append(draining, p)
+			continue
+		}
+		result = append(result, p)
+	}
+	return append(result, draining...), nil
+}
+
+// getNode return Node by name, nil if Pod is not bound to Node or Node does not exist anymore.
+// Nodes are memoized in nodes when it is not nil
+func (h *Handler) getNode(ctx context.Context, nodes map[string]*corev1.Node, name string) (*corev1.Node, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if node, ok := nodes[name]; ok {
+		return node, nil
+	}
+	node := &corev1.Node{}
+	err := h.client.Get(ctx, types.NamespacedName{Name: name}, node)
+	if apierrors.IsNotFound(err) {
+		node = nil
+	} else if err != nil {
+		return nil, err
+	}
+	if nodes != nil {
+		nodes[name] = node
+	}
+	return node, nil
+}
+
+// globalPath return domain path of Pods when workload is ranked globally: by band, by Node rank, by sort, by
+// multiple spread keys, in skew mode, by zone weights or zone preference. Nil is returned when each domain has
+// its own ladder
+func (h *Handler) globalPath(w *module.Workload) PathFunc {
+	_, weighted := w.GetAnnotations()[WeightsAnnotation]
+	_, preferred := w.GetAnnotations()[PreferenceAnnotation]
+	global := h.band != nil || h.nodeRank != nil || h.sort != nil || weighted || preferred || GetMode(w) == ModeSkew
+	if (h.band != nil || h.sort != nil) && !IsBandSpread(w) {
+		return func(*corev1.Node, *corev1.Pod) []string {
+			return nil
+		}
+	}
+	if h.labelSpread {
+		keys := SpreadKeys(w)
+		if len(keys) < 2 && !global {
+			return nil
+		}
+		return func(node *corev1.Node, _ *corev1.Pod) []string {
+			path := make([]string, len(keys))
+			for i, key := range keys {
+				path[i] = node.Labels[key]
+			}
+			return path
+		}
+	}
+	if !global {
+		return nil
+	}
+	return func(node *corev1.Node, pod *corev1.Pod) []string {
+		return []string{h.domain(node, pod, w)}
+	}
+}
+
+func (h *Handler) isDeploymentScope(w *module.Workload) bool {
+	if h.labelSpread {
+		return IsDeploymentScope(w)
+	}
+	return GetScope(w) == ScopeDeployment
+}
+
+// isForeign return true if Pod cost is not managed by controller. Pods with cached cost are managed
+// even if patch is not visible in informer cache yet
+func (h *Handler) isForeign(pod *corev1.Pod) bool {
+	if _, cached := h.cache.Get(pod.UID); cached {
+		return false
+	}
+	return controller.IsForeignCost(pod)
+}
+
+// costOf return cost of Pod. Cached value has priority until it is visible in informer cache
+func (h *Handler) costOf(pod *corev1.Pod) (int, bool) {
+	cost, exist := controller.GetPodDeletionCost(pod)
+	if v, cached := h.cache.Get(pod.UID); cached {
+		if exist && v == cost {
+			h.cache.Delete(pod.UID)
+		}
+		return v, true
+	}
+	return cost, exist
+}
+
+// domainOf return domain of Pod running on Node, empty if Node is not known
+func (h *Handler) domainOf(node *corev1.Node, pod *corev1.Pod, w *module.Workload) string {
+	if node == nil {
+		return ""
+	}
+	return h.domain(node, pod, w)
+}
+
+// movedDomain return true if domain of Pod differs from domain recorded when its cost was assigned.
+// Pods without recorded domain or with unknown domain are not moved
+func movedDomain(pod *corev1.Pod, domain string) bool {
+	previous, ok := controller.GetDomain(pod)
+	return ok && domain != "" && previous != domain
+}
+
+func (h *Handler) patchCost(ctx context.Context, pod *corev1.Pod, cost int, domain string) error {
+	if err := controller.PatchPodDeletionCost(ctx, h.client, pod, cost, domain); err != nil {
+		return fmt.Errorf("unable to apply cost to pod %s: %w", pod.Name, err)
+	}
 	return nil
 }
 
 func (h *Handler) listPodsInZone(
 	ctx context.Context,
 	log logr.Logger,
-	deployment *v1.Deployment,
+	w *module.Workload,
 	pod *corev1.Pod,
 	pods *[]corev1.Pod,
 ) error {
-	podRecZoneAnn, err := h.getPodAnnotation(ctx, pod, deployment)
+	podRecZoneAnn, err := h.getPodAnnotation(ctx, pod, w)
 	if err != nil {
 		return fmt.Errorf("unable to get pod annotation: %w", err)
 	}
+	return h.listPodsInDomain(ctx, log, w, pod, podRecZoneAnn, pods)
+}
+
+// listPodsInDomain list Pods of Pod scope running in domain
+func (h *Handler) listPodsInDomain(
+	ctx context.Context,
+	log logr.Logger,
+	w *module.Workload,
+	pod *corev1.Pod,
+	podRecZoneAnn string,
+	pods *[]corev1.Pod,
+) error {
 	podList := &corev1.PodList{}
-	err = listPodsByOwnerRSIndex(ctx, h.client, pod, podList)
-	if err != nil {
+	if err := h.listScopePods(ctx, w, pod, podList); err != nil {
 		return fmt.Errorf("unable to list pods by rs: %w", err)
 	}
 
 	nodeCache := make(map[string]*corev1.Node)
-	node := &corev1.Node{}
 	for _, p := range podList.Items {
-		nodeName := p.Spec.NodeName
-		cachedNode, ok := nodeCache[nodeName]
-		if !ok {
-			if err := h.client.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
-				return err
-			}
-			nodeCopy := node.DeepCopy()
-			nodeCache[nodeName] = nodeCopy
-			cachedNode = nodeCopy
+		// Pods not bound yet or left on deleted Node are in no domain
+		cachedNode, err := h.getNode(ctx, nodeCache, p.Spec.NodeName)
+		if err != nil {
+			return err
 		}
-		zoneAnn := GetSpreadByAnnotation(cachedNode, deployment)
-		if podRecZoneAnn != zoneAnn {
+		if cachedNode == nil {
+			continue
+		}
+		if podRecZoneAnn != h.domain(cachedNode, &p, w) {
 			continue
 		}
 		*pods = append(*pods, p)
@@ -124,7 +545,15 @@ func (h *Handler) listPodsInZone(
 	return nil
 }
 
-func (h *Handler) getPodAnnotation(ctx context.Context, pod *corev1.Pod, deployment *v1.Deployment) (string, error) {
+// listScopePods list Pods ranked together with Pod: Pods of its ReplicaSet or of the whole workload
+func (h *Handler) listScopePods(ctx context.Context, w *module.Workload, pod *corev1.Pod, list *corev1.PodList) error {
+	if h.isDeploymentScope(w) {
+		return controller.ListWorkloadPods(ctx, h.client, w, list)
+	}
+	return listPodsByOwnerRSIndex(ctx, h.client, pod, list)
+}
+
+func (h *Handler) getPodAnnotation(ctx context.Context, pod *corev1.Pod, w *module.Workload) (string, error) {
 	//Get zone for reconcile POD
 	node := &corev1.Node{}
 	if err := h.client.Get(ctx, types.NamespacedName{
@@ -132,7 +561,7 @@ func (h *Handler) getPodAnnotation(ctx context.Context, pod *corev1.Pod, deploym
 	}, node); err != nil {
 		return "", err
 	}
-	return GetSpreadByAnnotation(node, deployment), nil
+	return h.domain(node, pod, w), nil
 }
 
 func listPodsByOwnerRSIndex(ctx context.Context, c client.Client, pod *corev1.Pod, list *corev1.PodList) error {
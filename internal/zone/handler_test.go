@@ -0,0 +1,523 @@
+package zone_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/controller"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/testutil"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newNode(name, zoneName string) *corev1.Node {
+	return testutil.NewNode(name, map[string]string{zone.TopologyZoneAnnotation: zoneName})
+}
+
+// conflictOnce return interceptor failing first Apply with Conflict, as apply conditioned by stale resourceVersion
+func conflictOnce() interceptor.Funcs {
+	conflicted := false
+	return interceptor.Funcs{
+		Apply: func(ctx context.Context, c client.WithWatch, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+			if !conflicted {
+				conflicted = true
+				return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "pod", errors.New("stale"))
+			}
+			return c.Apply(ctx, obj, opts...)
+		},
+	}
+}
+
+func TestHandleCompact(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			UID:         "app",
+			Annotations: map[string]string{zone.ModeAnnotation: zone.ModeCompact},
+		},
+	}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	kept := testutil.NewPod("kept", rs.Name, "node-a", math.MaxInt32-2)
+	added := testutil.NewPod("added", rs.Name, "node-a", 0)
+	other := testutil.NewPod("other", rs.Name, "node-b", math.MaxInt32-5)
+	c := testutil.NewFakeClient(newNode("node-a", "a"), newNode("node-b", "b"), rs, kept, added, other)
+
+	h := zone.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), added, module.FromDeployment(dep)))
+
+	require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "kept"))
+	require.Equal(t, math.MaxInt32-1, testutil.GetCost(t, c, "added"))
+	require.Equal(t, math.MaxInt32-5, testutil.GetCost(t, c, "other"), "other zone must not be touched")
+}
+
+func TestHandleDeploymentScope(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			UID:         "app",
+			Annotations: map[string]string{zone.ScopeAnnotation: zone.ScopeDeployment},
+		},
+	}
+	oldRS := testutil.NewReplicaSet("app-old", dep)
+	newRS := testutil.NewReplicaSet("app-new", dep)
+	old := testutil.NewPod("old", oldRS.Name, "node-a", math.MaxInt32)
+	added := testutil.NewPod("added", newRS.Name, "node-a", 0)
+	c := testutil.NewFakeClient(newNode("node-a", "a"), oldRS, newRS, old, added)
+
+	h := zone.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), added, module.FromDeployment(dep)))
+
+	require.Equal(t, math.MaxInt32-1, testutil.GetCost(t, c, "added"))
+}
+
+func TestHandleForeignCost(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		policy      string
+		foreign     int
+		wantForeign int
+		wantAdded   int
+		wantManaged int
+		wantOwned   bool
+	}{
+		{
+			name:        "default mode counts foreign cost by default",
+			policy:      "",
+			foreign:     math.MaxInt32 - 1,
+			wantForeign: math.MaxInt32 - 1,
+			wantAdded:   math.MaxInt32 - 2,
+			wantManaged: math.MaxInt32,
+		},
+		{
+			name:        "default mode respect excludes foreign cost from ladder",
+			policy:      controller.ForeignCostRespect,
+			foreign:     math.MaxInt32 - 1,
+			wantForeign: math.MaxInt32 - 1,
+			wantAdded:   math.MaxInt32 - 1,
+			wantManaged: math.MaxInt32,
+		},
+		{
+			name:        "default mode override replaces foreign cost",
+			policy:      controller.ForeignCostOverride,
+			foreign:     math.MaxInt32 - 1,
+			wantForeign: math.MaxInt32 - 2,
+			wantAdded:   math.MaxInt32 - 1,
+			wantManaged: math.MaxInt32,
+			wantOwned:   true,
+		},
+		{
+			name:        "compact mode include keeps foreign cost as occupied slot",
+			mode:        zone.ModeCompact,
+			policy:      controller.ForeignCostInclude,
+			foreign:     math.MaxInt32 - 1,
+			wantForeign: math.MaxInt32 - 1,
+			wantAdded:   math.MaxInt32 - 2,
+			wantManaged: math.MaxInt32,
+		},
+		{
+			name:        "compact mode respect ranks managed pods only",
+			mode:        zone.ModeCompact,
+			policy:      controller.ForeignCostRespect,
+			foreign:     math.MaxInt32 - 1,
+			wantForeign: math.MaxInt32 - 1,
+			wantAdded:   math.MaxInt32 - 1,
+			wantManaged: math.MaxInt32,
+		},
+		{
+			name:        "compact mode includes foreign cost by default",
+			mode:        zone.ModeCompact,
+			policy:      "",
+			foreign:     math.MaxInt32 - 1,
+			wantForeign: math.MaxInt32 - 1,
+			wantAdded:   math.MaxInt32 - 2,
+			wantManaged: math.MaxInt32,
+		},
+		{
+			name:        "compact mode override takes ownership of foreign cost",
+			mode:        zone.ModeCompact,
+			policy:      controller.ForeignCostOverride,
+			foreign:     math.MaxInt32 - 1,
+			wantForeign: math.MaxInt32 - 1,
+			wantAdded:   math.MaxInt32 - 2,
+			wantManaged: math.MaxInt32,
+			wantOwned:   true,
+		},
+		{
+			name:        "skew mode includes foreign cost by default",
+			mode:        zone.ModeSkew,
+			policy:      "",
+			foreign:     5,
+			wantForeign: 5,
+			wantAdded:   math.MaxInt32 - 1,
+			wantManaged: math.MaxInt32,
+		},
+		{
+			name:        "skew mode override replaces foreign cost",
+			mode:        zone.ModeSkew,
+			policy:      controller.ForeignCostOverride,
+			foreign:     5,
+			wantForeign: math.MaxInt32 - 1,
+			wantAdded:   math.MaxInt32 - 2,
+			wantManaged: math.MaxInt32,
+			wantOwned:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "app",
+					Namespace: "default",
+					UID:       "app",
+					Annotations: map[string]string{
+						zone.ModeAnnotation:              tt.mode,
+						controller.ForeignCostAnnotation: tt.policy,
+					},
+				},
+			}
+			rs := testutil.NewReplicaSet("app-1", dep)
+			managed := testutil.NewPod("managed", rs.Name, "node-a", math.MaxInt32)
+			foreign := testutil.NewForeignPod("foreign", rs.Name, "node-a", tt.foreign)
+			added := testutil.NewPod("added", rs.Name, "node-a", 0)
+			c := testutil.NewFakeClient(newNode("node-a", "a"), rs, managed, foreign, added)
+
+			h := zone.NewHandler(c)
+			w := module.FromDeployment(dep)
+			require.NoError(t, h.Handle(context.Background(), logr.Discard(), added, w))
+			if tt.policy == controller.ForeignCostOverride {
+				require.NoError(t, h.Handle(context.Background(), logr.Discard(), foreign, w))
+			}
+
+			require.Equal(t, tt.wantManaged, testutil.GetCost(t, c, "managed"))
+			require.Equal(t, tt.wantForeign, testutil.GetCost(t, c, "foreign"))
+			require.Equal(t, tt.wantAdded, testutil.GetCost(t, c, "added"))
+			pod := &corev1.Pod{}
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "foreign"}, pod))
+			require.Equal(t, tt.wantOwned, controller.IsManaged(pod), "ownership of foreign cost")
+		})
+	}
+}
+
+func TestHandleProvisionalCost(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	managed := testutil.NewPod("managed", rs.Name, "node-a", math.MaxInt32)
+	provisional := testutil.NewPod("provisional", rs.Name, "node-a", 0)
+	controller.ApplyProvisionalCost(provisional, math.MaxInt32-1)
+	c := testutil.NewFakeClient(newNode("node-a", "a"), rs, managed, provisional)
+
+	h := zone.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), provisional, module.FromDeployment(dep)))
+
+	require.Equal(t, math.MaxInt32-1, testutil.GetCost(t, c, "provisional"))
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "provisional"}, pod))
+	require.False(t, controller.IsProvisional(pod), "provisional cost must be refined")
+	require.True(t, controller.IsManaged(pod))
+}
+
+func TestHandleConflict(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+	}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	provisional := testutil.NewPod("provisional", rs.Name, "node-a", 0)
+	controller.ApplyProvisionalCost(provisional, math.MaxInt32)
+	c := testutil.NewFakeClientBuilder(newNode("node-a", "a"), rs, provisional).
+		WithInterceptorFuncs(conflictOnce()).
+		Build()
+
+	h := zone.NewHandler(c)
+	err := h.Handle(context.Background(), logr.Discard(), provisional, module.FromDeployment(dep))
+	require.True(t, apierrors.IsConflict(errors.Unwrap(err)), "unexpected error: %v", err)
+
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "provisional"}, pod))
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), pod, module.FromDeployment(dep)))
+
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "provisional"}, pod))
+	require.False(t, controller.IsProvisional(pod), "retry must refine provisional cost")
+	require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "provisional"))
+}
+
+func TestHandleConflictRanking(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+	}{
+		{name: "compact", mode: zone.ModeCompact},
+		{name: "global", mode: zone.ModeSkew},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{
+					Name:        "app",
+					Namespace:   "default",
+					UID:         "app",
+					Annotations: map[string]string{zone.ModeAnnotation: tt.mode},
+				},
+			}
+			rs := testutil.NewReplicaSet("app-1", dep)
+			pod := testutil.NewPod("pod", rs.Name, "node-a", math.MaxInt32-3)
+			c := testutil.NewFakeClientBuilder(newNode("node-a", "a"), rs, pod).
+				WithInterceptorFuncs(conflictOnce()).
+				Build()
+
+			h := zone.NewHandler(c)
+			require.Error(t, h.Handle(context.Background(), logr.Discard(), pod, module.FromDeployment(dep)))
+
+			pod = &corev1.Pod{}
+			require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "pod"}, pod))
+			require.NoError(t, h.Handle(context.Background(), logr.Discard(), pod, module.FromDeployment(dep)))
+			require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "pod"), "retry must correct cost")
+		})
+	}
+}
+
+func TestHandleDeletedNode(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+	}{
+		{name: "default"},
+		{name: "compact", mode: zone.ModeCompact},
+		{name: "global", mode: zone.ModeSkew},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{
+					Name:        "app",
+					Namespace:   "default",
+					UID:         "app",
+					Annotations: map[string]string{zone.ModeAnnotation: tt.mode},
+				},
+			}
+			rs := testutil.NewReplicaSet("app-1", dep)
+			a := testutil.NewPod("a", rs.Name, "node-a", 0)
+			orphan := testutil.NewPod("orphan", rs.Name, "node-gone", math.MaxInt32)
+			c := testutil.NewFakeClient(newNode("node-a", "a"), rs, a, orphan)
+
+			h := zone.NewHandler(c)
+			require.NoError(t, h.Handle(context.Background(), logr.Discard(), a, module.FromDeployment(dep)))
+
+			require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "a"))
+			require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "orphan"), "pod on deleted node must not be touched")
+		})
+	}
+}
+
+func TestHandleHierarchical(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "app",
+			Namespace: "default",
+			UID:       "app",
+			Annotations: map[string]string{
+				zone.SpreadByAnnotation: "topology.kubernetes.io/region,topology.kubernetes.io/zone",
+			},
+		},
+	}
+	newRegionNode := func(name, region, zoneName string) *corev1.Node {
+		n := newNode(name, zoneName)
+		n.Labels["topology.kubernetes.io/region"] = region
+		return n
+	}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	pods := []*corev1.Pod{
+		testutil.NewPod("r1-a-1", rs.Name, "node-r1-a", math.MaxInt32),
+		testutil.NewPod("r1-a-2", rs.Name, "node-r1-a", math.MaxInt32-1),
+		testutil.NewPod("r1-b-1", rs.Name, "node-r1-b", math.MaxInt32-2),
+		testutil.NewPod("r2-a-1", rs.Name, "node-r2-a", 0),
+	}
+	objs := []client.Object{
+		newRegionNode("node-r1-a", "r1", "a"),
+		newRegionNode("node-r1-b", "r1", "b"),
+		newRegionNode("node-r2-a", "r2", "a"),
+		rs,
+	}
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	c := testutil.NewFakeClient(objs...)
+
+	h := zone.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), pods[3], module.FromDeployment(dep)))
+
+	// r2 is smaller region, its pod is protected first in each round; r1 pod from zone a is removed first
+	require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "r2-a-1"))
+	require.Equal(t, math.MaxInt32-1, testutil.GetCost(t, c, "r1-b-1"))
+	require.Equal(t, math.MaxInt32-2, testutil.GetCost(t, c, "r1-a-1"))
+	require.Equal(t, math.MaxInt32-3, testutil.GetCost(t, c, "r1-a-2"))
+}
+
+func TestHandleSkew(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			UID:         "app",
+			Annotations: map[string]string{zone.ModeAnnotation: zone.ModeSkew},
+		},
+	}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	objs := []client.Object{newNode("node-a", "a"), newNode("node-b", "b"), rs}
+	for i := range 5 {
+		objs = append(objs, testutil.NewPod(fmt.Sprintf("a-%d", i), rs.Name, "node-a", math.MaxInt32-i))
+	}
+	for i := range 2 {
+		objs = append(objs, testutil.NewPod(fmt.Sprintf("b-%d", i), rs.Name, "node-b", math.MaxInt32-i))
+	}
+	c := testutil.NewFakeClient(objs...)
+
+	h := zone.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), objs[3].(*corev1.Pod), module.FromDeployment(dep)))
+
+	// zones alternate while balanced, surplus pods of zone a are at the bottom and removed first
+	want := map[string]int{
+		"b-0": math.MaxInt32,
+		"a-0": math.MaxInt32 - 1,
+		"b-1": math.MaxInt32 - 2,
+		"a-1": math.MaxInt32 - 3,
+		"a-2": math.MaxInt32 - 4,
+		"a-3": math.MaxInt32 - 5,
+		"a-4": math.MaxInt32 - 6,
+	}
+	for name, cost := range want {
+		require.Equal(t, cost, testutil.GetCost(t, c, name), name)
+	}
+}
+
+func TestHandleTopologySpreadConstraints(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"app": "app"}},
+				Spec: corev1.PodSpec{
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						TopologyKey:    "rack",
+						LabelSelector:  &v1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+						MatchLabelKeys: []string{appsv1.DefaultDeploymentUniqueLabelKey},
+					}},
+				},
+			},
+		},
+	}
+	newRackNode := func(name, rack string) *corev1.Node {
+		n := newNode(name, "a")
+		n.Labels["rack"] = rack
+		return n
+	}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	first := testutil.NewPod("first", rs.Name, "node-1", math.MaxInt32)
+	added := testutil.NewPod("added", rs.Name, "node-2", 0)
+	c := testutil.NewFakeClient(newRackNode("node-1", "r1"), newRackNode("node-2", "r2"), rs, first, added)
+
+	h := zone.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), added, module.FromDeployment(dep)))
+
+	require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "added"), "pods in different racks have own ladders")
+}
+
+func TestHandleDraining(t *testing.T) {
+	dep := &appsv1.Deployment{ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"}}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	first := testutil.NewPod("first", rs.Name, "node-a", math.MaxInt32)
+	second := testutil.NewPod("second", rs.Name, "node-a", math.MaxInt32-1)
+	cordoned := newNode("node-b", "a")
+	cordoned.Spec.Unschedulable = true
+	c := testutil.NewFakeClient(newNode("node-a", "a"), cordoned, rs, first, second)
+
+	h := zone.NewHandler(c)
+	w := module.FromDeployment(dep)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), first, w))
+	require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "first"))
+
+	// pod on cordoned node is moved below ladder, its slot is freed
+	draining := testutil.NewPod("draining", rs.Name, "node-b", math.MaxInt32-2)
+	require.NoError(t, c.Create(context.Background(), draining))
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), draining, w))
+	require.Equal(t, controller.DrainingCost, testutil.GetCost(t, c, "draining"))
+
+	// cost is restored once node recovers
+	cordoned.Spec.Unschedulable = false
+	require.NoError(t, c.Update(context.Background(), cordoned))
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "draining"}, draining))
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), draining, w))
+	require.Equal(t, math.MaxInt32-2, testutil.GetCost(t, c, "draining"))
+}
+
+func TestHandleDrainingCompact(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "default",
+			UID:         "app",
+			Annotations: map[string]string{zone.ModeAnnotation: zone.ModeCompact},
+		},
+	}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	tainted := newNode("node-b", "a")
+	tainted.Spec.Taints = []corev1.Taint{{Key: "karpenter.sh/disruption", Value: "disrupting", Effect: corev1.TaintEffectNoSchedule}}
+	first := testutil.NewPod("first", rs.Name, "node-b", math.MaxInt32)
+	second := testutil.NewPod("second", rs.Name, "node-a", math.MaxInt32-1)
+	c := testutil.NewFakeClient(newNode("node-a", "a"), tainted, rs, first, second)
+
+	h := zone.NewHandler(c)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), second, module.FromDeployment(dep)))
+
+	// pod on node being disrupted is at the bottom of zone ladder
+	require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "second"))
+	require.Equal(t, math.MaxInt32-1, testutil.GetCost(t, c, "first"))
+}
+
+func TestHandleNodeLabelChange(t *testing.T) {
+	dep := &appsv1.Deployment{ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: "default", UID: "app"}}
+	rs := testutil.NewReplicaSet("app-1", dep)
+	nodeB := newNode("node-b", "b")
+	inB := testutil.NewPod("in-b", rs.Name, "node-b", math.MaxInt32)
+	moved := testutil.NewPod("moved", rs.Name, "node-c", 0)
+	c := testutil.NewFakeClient(newNode("node-a", "a"), nodeB, newNode("node-c", "a"), rs, inB, moved)
+
+	h := zone.NewHandler(c)
+	w := module.FromDeployment(dep)
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), moved, w))
+	require.Equal(t, math.MaxInt32, testutil.GetCost(t, c, "moved"))
+	// synced cost clears cache
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), moved, w))
+
+	// node-c is relabeled to zone b, cost is recomputed within zone b
+	nodeC := &corev1.Node{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "node-c"}, nodeC))
+	nodeC.Labels[zone.TopologyZoneAnnotation] = "b"
+	require.NoError(t, c.Update(context.Background(), nodeC))
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "moved"}, moved))
+	require.NoError(t, h.Handle(context.Background(), logr.Discard(), moved, w))
+	require.Equal(t, math.MaxInt32-1, testutil.GetCost(t, c, "moved"))
+	domain, _ := controller.GetDomain(moved)
+	require.Equal(t, "b", domain)
+}
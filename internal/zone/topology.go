@@ -0,0 +1,77 @@
+package zone
+
+import (
+	"slices"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// TopologySpreadKeys return topology keys of topologySpreadConstraints of workload Pod template, in order
+// of constraints. Only constraints whose labelSelector selects Pods of the template are used.
+// PerReplicaSet is true when all used constraints count Pods of single ReplicaSet only, i.e. their matchLabelKeys
+// contain label added to Pods by ReplicaSet owner (e.g. pod-template-hash), which is missing in template
+func TopologySpreadKeys(w *module.Workload) (keys []string, perReplicaSet bool) {
+	template := w.Template
+	perReplicaSet = true
+	for _, c := range template.Spec.TopologySpreadConstraints {
+		if !selectsTemplate(c, template.Labels) {
+			continue
+		}
+		if !slices.Contains(keys, c.TopologyKey) {
+			keys = append(keys, c.TopologyKey)
+		}
+		perReplicaSet = perReplicaSet && hasOwnerLabelKey(c, template.Labels)
+	}
+	if len(keys) == 0 {
+		return nil, false
+	}
+	return keys, perReplicaSet
+}
+
+func selectsTemplate(c corev1.TopologySpreadConstraint, templateLabels map[string]string) bool {
+	if c.LabelSelector == nil || c.TopologyKey == "" {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(c.LabelSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(templateLabels))
+}
+
+func hasOwnerLabelKey(c corev1.TopologySpreadConstraint, templateLabels map[string]string) bool {
+	for _, key := range c.MatchLabelKeys {
+		if _, ok := templateLabels[key]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SpreadKeys return label keys used for spreading: SpreadByAnnotation keys, topology keys of
+// topologySpreadConstraints of Pod template when annotation is not set, or TopologyZoneAnnotation
+func SpreadKeys(w *module.Workload) []string {
+	if keys := GetSpreadByKeys(w); len(keys) > 0 {
+		return keys
+	}
+	if keys, _ := TopologySpreadKeys(w); len(keys) > 0 {
+		return keys
+	}
+	return []string{TopologyZoneAnnotation}
+}
+
+// IsDeploymentScope return true if Pods of all ReplicaSets of workload are ranked together. Scope is selected
+// by ScopeAnnotation, or derived from topologySpreadConstraints used for spreading
+func IsDeploymentScope(w *module.Workload) bool {
+	if _, ok := w.GetAnnotations()[ScopeAnnotation]; ok {
+		return GetScope(w) == ScopeDeployment
+	}
+	if len(GetSpreadByKeys(w)) > 0 {
+		return false
+	}
+	keys, perReplicaSet := TopologySpreadKeys(w)
+	return len(keys) > 0 && !perReplicaSet
+}
@@ -0,0 +1,92 @@
+package zone_test
+
+import (
+	"testing"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	"github.com/lablabs/pod-deletion-cost-controller/internal/zone"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTopologySpreadKeys(t *testing.T) {
+	appLabels := map[string]string{"app": "my-app"}
+	constraint := func(key string, selector map[string]string, matchLabelKeys ...string) corev1.TopologySpreadConstraint {
+		c := corev1.TopologySpreadConstraint{TopologyKey: key, MatchLabelKeys: matchLabelKeys}
+		if selector != nil {
+			c.LabelSelector = &v1.LabelSelector{MatchLabels: selector}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		constraints     []corev1.TopologySpreadConstraint
+		wantKeys        []string
+		wantDeployScope bool
+	}{
+		{
+			name:        "no constraints → default zone label",
+			constraints: nil,
+			wantKeys:    []string{zone.TopologyZoneAnnotation},
+		},
+		{
+			name:            "constraint selecting template",
+			constraints:     []corev1.TopologySpreadConstraint{constraint("rack", appLabels)},
+			wantKeys:        []string{"rack"},
+			wantDeployScope: true,
+		},
+		{
+			name: "constraints in order, foreign selector and nil selector ignored",
+			constraints: []corev1.TopologySpreadConstraint{
+				constraint("other", map[string]string{"app": "other"}),
+				constraint("nil-selector", nil),
+				constraint(zone.TopologyZoneAnnotation, appLabels),
+				constraint("kubernetes.io/hostname", appLabels),
+			},
+			wantKeys:        []string{zone.TopologyZoneAnnotation, "kubernetes.io/hostname"},
+			wantDeployScope: true,
+		},
+		{
+			name: "matchLabelKeys with pod-template-hash counts single ReplicaSet",
+			constraints: []corev1.TopologySpreadConstraint{
+				constraint("rack", appLabels, appsv1.DefaultDeploymentUniqueLabelKey),
+			},
+			wantKeys:        []string{"rack"},
+			wantDeployScope: false,
+		},
+		{
+			name:        "annotation has precedence",
+			annotations: map[string]string{zone.SpreadByAnnotation: "custom"},
+			constraints: []corev1.TopologySpreadConstraint{constraint("rack", appLabels)},
+			wantKeys:    []string{"custom"},
+		},
+		{
+			name:            "scope annotation has precedence",
+			annotations:     map[string]string{zone.ScopeAnnotation: zone.ScopeDeployment},
+			constraints:     []corev1.TopologySpreadConstraint{constraint("rack", appLabels, "pod-template-hash")},
+			wantKeys:        []string{"rack"},
+			wantDeployScope: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: v1.ObjectMeta{Name: "my-app", Annotations: tt.annotations},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{Labels: appLabels},
+						Spec:       corev1.PodSpec{TopologySpreadConstraints: tt.constraints},
+					},
+				},
+			}
+			w := module.FromDeployment(dep)
+			require.Equal(t, tt.wantKeys, zone.SpreadKeys(w))
+			require.Equal(t, tt.wantDeployScope, zone.IsDeploymentScope(w))
+		})
+	}
+}
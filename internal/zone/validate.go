@@ -0,0 +1,46 @@
+package zone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lablabs/pod-deletion-cost-controller/internal/module"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Validate validates zone annotations of workload. Spread-by key carried by no Node is reported as warning only,
+// Nodes can join cluster later
+func (h *Handler) Validate(ctx context.Context, w *module.Workload) ([]string, field.ErrorList) {
+	annotations := field.NewPath("metadata", "annotations")
+	errs := field.ErrorList{}
+	if mode, ok := w.GetAnnotations()[ModeAnnotation]; ok && mode != ModeCompact && mode != ModeSkew {
+		errs = append(errs, field.NotSupported(annotations.Key(ModeAnnotation), mode, []string{ModeCompact, ModeSkew}))
+	}
+	if scope, ok := w.GetAnnotations()[ScopeAnnotation]; ok && scope != ScopeDeployment {
+		errs = append(errs, field.NotSupported(annotations.Key(ScopeAnnotation), scope, []string{ScopeDeployment}))
+	}
+	if mode, ok := w.GetAnnotations()[PreferenceModeAnnotation]; ok && mode != PreferenceTieBreaker && mode != PreferenceStrict {
+		errs = append(errs, field.NotSupported(annotations.Key(PreferenceModeAnnotation), mode,
+			[]string{PreferenceTieBreaker, PreferenceStrict}))
+	}
+	if value, ok := w.GetAnnotations()[BandSpreadAnnotation]; ok && value != "true" && value != "false" {
+		errs = append(errs, field.NotSupported(annotations.Key(BandSpreadAnnotation), value, []string{"true", "false"}))
+	}
+	if _, err := GetWeights(w); err != nil {
+		errs = append(errs, field.Invalid(annotations.Key(WeightsAnnotation), w.GetAnnotations()[WeightsAnnotation], err.Error()))
+	}
+
+	warnings := make([]string, 0)
+	for _, key := range GetSpreadByKeys(w) {
+		nodes := &corev1.NodeList{}
+		if err := h.client.List(ctx, nodes, client.HasLabels{key}, client.Limit(1)); err != nil {
+			return append(warnings, fmt.Sprintf("unable to verify %s: %s", SpreadByAnnotation, err)), errs
+		}
+		if len(nodes.Items) == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: no node has label %q", SpreadByAnnotation, key))
+		}
+	}
+	return warnings, errs
+}